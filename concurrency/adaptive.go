@@ -0,0 +1,104 @@
+package typed_goroutine
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Adjust the number of jobs running at the same time between min and max
+// from how jobs fare, starting at min. Every job that succeeds in its usual
+// time raises the limit by one over the current limit, so it grows by one
+// per round of jobs. A job that fails or panics, or takes more than twice
+// the average duration of the jobs so far, halves the limit, at most once
+// per round so jobs started before are not counted against the new limit.
+// MaxWorkers and Stats report the current limit. Which errors back off can
+// be narrowed WithAdaptiveBackoffIf.
+func WithAdaptiveConcurrency[T any](min, max uint) Option[T] {
+	return func(p *Pool[T]) error {
+		if min == 0 || max < min {
+			return fmt.Errorf("%w: adaptive concurrency needs 0 < min <= max", ErrInvalidOption)
+		}
+		p.adaptive = &adaptive{min: float64(min), max: float64(max)}
+		return nil
+	}
+}
+
+// Only back off WithAdaptiveConcurrency for errors for which backoff reports
+// true, called with the *JobError of the job. Panics always back off. A
+// panic in backoff is passed to the panic handler and the error does not
+// back off.
+func WithAdaptiveBackoffIf[T any](backoff func(err error) bool) Option[T] {
+	return func(p *Pool[T]) error {
+		if backoff == nil {
+			return fmt.Errorf("%w: nil adaptive backoff predicate", ErrInvalidOption)
+		}
+		p.adaptiveIf = backoff
+		return nil
+	}
+}
+
+// Additive increase, multiplicative decrease of the limit of a pool
+type adaptive struct {
+	min, max float64
+	mu       sync.Mutex
+	limit    float64
+	// Moving average of the durations of the jobs that did not fail
+	average time.Duration
+	// Jobs recorded since the limit was halved last and how many must be
+	// before it may be halved again
+	sinceCut, round int
+}
+
+// Limit to start with
+func (a *adaptive) reset() uint {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.limit = a.min
+	a.average = 0
+	a.sinceCut, a.round = 0, 0
+	return uint(a.min)
+}
+
+// Count the outcome of a job that ran and return the new limit
+func (a *adaptive) record(failed bool, duration time.Duration) uint {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.limit == 0 {
+		a.limit = a.min
+	}
+	a.sinceCut++
+	spike := a.average > 0 && duration > 2*a.average
+	// A lasting change of the durations moves the average along
+	if !failed {
+		if a.average == 0 {
+			a.average = duration
+		} else {
+			a.average += (duration - a.average) / 8
+		}
+	}
+	if failed || spike {
+		if a.sinceCut >= a.round {
+			a.round = int(a.limit)
+			a.limit = math.Max(a.min, a.limit/2)
+			a.sinceCut = 0
+		}
+		return uint(a.limit)
+	}
+	a.limit = math.Min(a.max, a.limit+1/a.limit)
+	return uint(a.limit)
+}
+
+// Feed the result of a job to the adaptive limit and apply it
+func (p *Pool[T]) adapt(ctx context.Context, job queuedJob[T], result Result[T]) {
+	failed := !result.OK
+	if failed && result.Panic == nil && !isPanicError(result.Error) && p.adaptiveIf != nil {
+		failed = false
+		p.callHook(ctx, job, func() { failed = p.adaptiveIf(result.Error) })
+	}
+	if limit := p.adaptive.record(failed, result.Duration); limit != p.MaxWorkers() {
+		p.SetMaxWorkers(limit)
+	}
+}
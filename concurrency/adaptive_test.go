@@ -0,0 +1,69 @@
+package typed_goroutine_test
+
+import (
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+	"github.com/demy076/typed_goroutines/concurrency/typedpooltest"
+)
+
+// Simulate a downstream that takes 10ms per call and fails calls beyond
+// its capacity, advancing the fake clock once every running job waits for
+// it, and check that the limit settles around the capacity
+func TestAdaptiveConcurrencyConverges(t *testing.T) {
+	const capacity, jobs = 8, 600
+	clock := typedpooltest.NewClock(time.Unix(0, 0))
+	pool := typed_goroutine.NewPool[int](jobs, 0,
+		typed_goroutine.WithClock[int](clock),
+		typed_goroutine.WithAdaptiveConcurrency[int](1, 32),
+	)
+	errOverloaded := errors.New("overloaded")
+	var calls atomic.Int32
+	for range jobs {
+		if _, err := pool.AddJob(func() (*int, error) {
+			defer calls.Add(-1)
+			if calls.Add(1) > capacity {
+				return nil, errOverloaded
+			}
+			clock.Sleep(10 * time.Millisecond)
+			return nil, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	var limits []int
+	deadline := time.Now().Add(5 * time.Second)
+	for pool.State() == typed_goroutine.PoolRunning {
+		if time.Now().After(deadline) {
+			t.Fatal("simulation did not finish")
+		}
+		stats := pool.Stats()
+		// Wait for the pool to start as many jobs as it may and for all of
+		// them to wait for the clock
+		full := stats.Running >= stats.MaxWorkers || stats.Queued == 0
+		if stats.Running == 0 || !full || clock.Timers() < stats.Running {
+			runtime.Gosched()
+			continue
+		}
+		limits = append(limits, stats.MaxWorkers)
+		clock.Advance(10 * time.Millisecond)
+	}
+	pool.Wait()
+	if len(limits) < 20 {
+		t.Fatalf("only %d ticks of the clock", len(limits))
+	}
+	// Past the initial climb the limit stays between half the capacity
+	// and one over it
+	for _, limit := range limits[len(limits)/2:] {
+		if limit < capacity/2 || limit > capacity+1 {
+			t.Fatalf("limit %d after settling, want it around the capacity of %d, limits were %v", limit, capacity, limits)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package typed_goroutine
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// State of the circuit breaker of a pool
+type CircuitState int
+
+const (
+	// Jobs are started normally
+	CircuitClosed CircuitState = iota
+	// Jobs are skipped with ErrCircuitOpen
+	CircuitOpen
+	// A single job probes whether jobs succeed again, others are skipped
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// Stop starting jobs after consecutiveFailures jobs in a row failed or
+// panicked, the jobs that have not started yet are skipped with
+// ErrCircuitOpen. A job that succeeds resets the count. With a positive
+// cooldown and WithDynamicSubmission, the first job started once cooldown
+// passed probes again: if it succeeds jobs are started normally again,
+// otherwise the breaker stays open for another cooldown.
+func WithCircuitBreaker[T any](consecutiveFailures int, cooldown time.Duration) Option[T] {
+	return func(p *Pool[T]) error {
+		if consecutiveFailures <= 0 {
+			return fmt.Errorf("%w: circuit breaker needs a positive number of failures", ErrInvalidOption)
+		}
+		if cooldown < 0 {
+			return fmt.Errorf("%w: negative circuit breaker cooldown", ErrInvalidOption)
+		}
+		p.breaker = &breaker{threshold: consecutiveFailures, cooldown: cooldown}
+		return nil
+	}
+}
+
+// Counts consecutive failures and decides whether jobs may start
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int
+	openedAt  time.Time
+}
+
+// Whether a job may start, a job allowed after the cooldown is the probe.
+// Only probes if probe is set.
+func (b *breaker) allow(probe bool, now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitClosed:
+		return true
+	case CircuitOpen:
+		if !probe || b.cooldown == 0 || now.Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		return true
+	}
+	return false
+}
+
+// Count the outcome of a job that ran
+func (b *breaker) record(ok bool, now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ok {
+		b.failures = 0
+		b.state = CircuitClosed
+		return
+	}
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		b.state = CircuitOpen
+		b.openedAt = now
+	}
+}
+
+func (b *breaker) current() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *breaker) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.state = CircuitClosed
+	b.failures = 0
+}
@@ -0,0 +1,177 @@
+package typed_goroutine
+
+import (
+	"container/list"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Results of keyed jobs kept across runs of a pool, see WithCache. It is
+// used from several goroutines at once.
+type Cache[T any] interface {
+	// Result stored for key, reports false if there is none or it expired
+	Get(key string) (Result[T], bool)
+	// Store the result of key, it expires after ttl unless ttl is zero
+	Set(key string, result Result[T], ttl time.Duration)
+}
+
+// Look up every job added with a key in cache before it is started. A hit
+// is reported right away with the cached result labelled with the job,
+// without taking a worker or waiting for the rate limit, and has no
+// attempts. On a miss the job runs and its result is stored if it
+// succeeded, errors are only cached WithCachedErrors and panics never. The
+// cache is kept by Reset. NewLRUCache creates the default implementation.
+func WithCache[T any](cache Cache[T]) Option[T] {
+	return func(p *Pool[T]) error {
+		if cache == nil {
+			return fmt.Errorf("%w: nil cache", ErrInvalidOption)
+		}
+		p.cache = cache
+		return nil
+	}
+}
+
+// Also cache the results of keyed jobs that failed, for ttl, see WithCache
+func WithCachedErrors[T any](ttl time.Duration) Option[T] {
+	return func(p *Pool[T]) error {
+		if ttl <= 0 {
+			return fmt.Errorf("%w: cached errors need a positive ttl", ErrInvalidOption)
+		}
+		p.cachedErrorsTTL = ttl
+		return nil
+	}
+}
+
+// Report a keyed job with its cached result, reports false on a miss or
+// if the job already started and yielded
+func (p *Pool[T]) fromCache(job queuedJob[T]) bool {
+	if p.cache == nil || job.key == "" || job.resumed {
+		return false
+	}
+	result, ok := p.cache.Get(job.key)
+	if !ok {
+		p.counters.cacheMisses.Add(1)
+		return false
+	}
+	p.counters.cacheHits.Add(1)
+	if !job.handle.start() {
+		p.skip(job, ErrJobCancelled)
+		return true
+	}
+	result = result.forJob(job)
+	// Only what the job reported carries over, it did not run this time
+	result.Meta = Meta{Warnings: result.Warnings, RetriesExhausted: result.RetriesExhausted, Worker: -1}
+	p.emit(job, result)
+	return true
+}
+
+// Store the result of a keyed job that ran
+func (p *Pool[T]) remember(job queuedJob[T], result Result[T]) {
+	switch {
+	case result.Panic != nil || isPanicError(result.Error):
+		// Panics are never cached
+	case result.Error == nil:
+		p.cache.Set(job.key, result, 0)
+	case p.cachedErrorsTTL > 0:
+		p.cache.Set(job.key, result, p.cachedErrorsTTL)
+	}
+}
+
+// Cache holding the results of at most size keys in memory, evicting the
+// least recently used one to make room. Entries expire by the real clock
+// unless created with LRUCacheClock, the clock of a pool the cache is
+// given to does not matter, so pools sharing it agree on when an entry
+// expires.
+type LRUCache[T any] struct {
+	size  int
+	clock Clock
+	mu    sync.Mutex
+	order *list.List
+	byKey map[string]*list.Element
+}
+
+// Entry of an LRUCache, zero expiresAt never expires
+type lruEntry[T any] struct {
+	key       string
+	result    Result[T]
+	expiresAt time.Time
+}
+
+// Option of NewLRUCache
+type LRUCacheOption func(*lruConfig)
+
+type lruConfig struct {
+	clock Clock
+}
+
+// Measure the time entries expire by with clock, for example the fake
+// clock of typedpooltest also given to the pools WithClock. A nil clock
+// keeps the real one.
+func LRUCacheClock(clock Clock) LRUCacheOption {
+	return func(c *lruConfig) {
+		if clock != nil {
+			c.clock = clock
+		}
+	}
+}
+
+// Create a cache holding at most size results, at least one
+func NewLRUCache[T any](size int, opts ...LRUCacheOption) *LRUCache[T] {
+	config := lruConfig{clock: realClock{}}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	return &LRUCache[T]{
+		size:  max(size, 1),
+		clock: config.clock,
+		order: list.New(),
+		byKey: make(map[string]*list.Element),
+	}
+}
+
+// Result stored for key, an expired one is dropped
+func (c *LRUCache[T]) Get(key string) (Result[T], bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.byKey[key]
+	if !ok {
+		return Result[T]{}, false
+	}
+	entry := elem.Value.(*lruEntry[T])
+	if !entry.expiresAt.IsZero() && !c.clock.Now().Before(entry.expiresAt) {
+		c.order.Remove(elem)
+		delete(c.byKey, key)
+		return Result[T]{}, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.result, true
+}
+
+// Store the result of key, replacing the one stored before
+func (c *LRUCache[T]) Set(key string, result Result[T], ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &lruEntry[T]{key: key, result: result}
+	if ttl > 0 {
+		entry.expiresAt = c.clock.Now().Add(ttl)
+	}
+	if elem, ok := c.byKey[key]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.byKey[key] = c.order.PushFront(entry)
+	if c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.byKey, oldest.Value.(*lruEntry[T]).key)
+	}
+}
+
+// Number of results stored, including expired ones not looked up since
+func (c *LRUCache[T]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
@@ -0,0 +1,122 @@
+package typed_goroutine
+
+import (
+	"context"
+	"fmt"
+	"iter"
+)
+
+// Run the pool with ctx on jobs received from a channel until it is closed
+// or ctx is done, Wait returns once the last received job finished. Jobs
+// are only received while the queue of the pool is empty, so a producer
+// blocks on send while every worker is busy. Jobs added before are run as
+// well. Returns ErrAlreadyRunning or ErrPoolFinished if the pool was
+// started before, a pool created WithSerial cannot run from a channel.
+func (p *Pool[T]) RunFromChannel(ctx context.Context, jobs <-chan func() (*T, error)) error {
+	return p.runFed(ctx, func(done <-chan struct{}) {
+		p.feed(ctx, jobs, done)
+	})
+}
+
+// Run the pool with ctx on the jobs of seq like RunFromChannel, taking the
+// next job from seq only while the queue of the pool is empty. Combined
+// with Results this runs any number of jobs in memory bound by the number
+// of workers. seq is iterated on its own goroutine and stopped once ctx is
+// done or the pool is stopped.
+func (p *Pool[T]) RunFromSeq(ctx context.Context, seq iter.Seq[func() (*T, error)]) error {
+	return p.runFed(ctx, func(<-chan struct{}) {
+		for job := range seq {
+			if !p.awaitEmptyQueue(ctx) {
+				return
+			}
+			p.mu.Lock()
+			// A pool stopped in the meantime no longer schedules any job,
+			// so the one just created is dropped
+			if !p.closed {
+				p.enqueue(queuedJob[T]{plain: job})
+			}
+			p.mu.Unlock()
+		}
+	})
+}
+
+// Run the pool with ctx on total jobs created by gen when a worker is about
+// to become free, see RunFromSeq. Jobs are created in order, so the index
+// of the result of gen(i) is i plus the number of jobs added before.
+func (p *Pool[T]) RunGenerated(ctx context.Context, total int, gen func(i int) func() (*T, error)) error {
+	return p.RunFromSeq(ctx, func(yield func(func() (*T, error)) bool) {
+		for i := 0; i < total; i++ {
+			if !yield(gen(i)) {
+				return
+			}
+		}
+	})
+}
+
+// Start the pool and queue jobs with feed on its own goroutine, the pool
+// is closed once feed returns
+func (p *Pool[T]) runFed(ctx context.Context, feed func(done <-chan struct{})) error {
+	if p.serial {
+		return fmt.Errorf("%w: a serial pool cannot be fed while running", ErrInvalidOption)
+	}
+	p.mu.Lock()
+	if p.running {
+		err := p.startedErr()
+		p.mu.Unlock()
+		return err
+	}
+	p.feeding = true
+	p.mu.Unlock()
+	started, err := p.start(ctx)
+	if !started {
+		return err
+	}
+	p.mu.Lock()
+	done := p.done
+	p.mu.Unlock()
+	stop := context.AfterFunc(ctx, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.dequeued.Broadcast()
+	})
+	go func() {
+		defer stop()
+		defer p.Close()
+		feed(done)
+	}()
+	return err
+}
+
+// Queue jobs from the channel one at a time whenever the queue is empty
+func (p *Pool[T]) feed(ctx context.Context, jobs <-chan func() (*T, error), done <-chan struct{}) {
+	for p.awaitEmptyQueue(ctx) {
+		select {
+		case job, ok := <-jobs:
+			if !ok {
+				return
+			}
+			p.mu.Lock()
+			// A pool stopped in the meantime no longer schedules any job,
+			// so the one just received is dropped
+			if !p.closed {
+				p.enqueue(queuedJob[T]{plain: job})
+			}
+			p.mu.Unlock()
+		case <-ctx.Done():
+			return
+		case <-done:
+			return
+		}
+	}
+}
+
+// Wait until the queue is empty, reports false once ctx is done or the
+// pool no longer accepts jobs
+func (p *Pool[T]) awaitEmptyQueue(ctx context.Context) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for len(p.queue) > 0 && ctx.Err() == nil && !p.closed {
+		p.dequeued.Wait()
+	}
+	return !p.closed && ctx.Err() == nil
+}
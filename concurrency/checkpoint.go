@@ -0,0 +1,126 @@
+package typed_goroutine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Let the jobs of the pool yield their worker at every nth Checkpoint while
+// other jobs wait for one. Such a job returns Continue with a function
+// that picks up where it left off, which is queued again behind the jobs
+// waiting at its priority and the jobs that yielded before it, and runs
+// once a worker is free. Only the state the function holds carries over,
+// every run gets a fresh context, timeout and retries, and OnJobStart and
+// WithJobContext see every run, while the collector and the logger see the
+// job start once and end with its last run. The handle, index, name, group
+// and policy stay the same, and the job has one result describing its last
+// run. A job that yielded and is queued again is skipped like any other
+// queued job if the pool stops, but can no longer be cancelled.
+func WithPreemptiveCheckpoints[T any](n uint) Option[T] {
+	return func(p *Pool[T]) error {
+		if n == 0 {
+			return fmt.Errorf("%w: checkpoints between yields must be positive", ErrInvalidOption)
+		}
+		p.preemptEvery = n
+		return nil
+	}
+}
+
+// Check at a safe point of a long running job whether it should stop.
+// Returns the cause once the pool was stopped or the context of the run is
+// done, and ErrJobYielded if the job should yield its worker
+// WithPreemptiveCheckpoints because another job waits for one. Returns nil right away otherwise, and when
+// ctx does not belong to a job.
+func Checkpoint(ctx context.Context) error {
+	scope, ok := ctx.Value(jobInfoKey{}).(*jobScope)
+	if !ok || scope.pool == nil {
+		return nil
+	}
+	return scope.pool.checkpoint(ctx, scope)
+}
+
+// Pool a running job belongs to, for Checkpoint
+type checkpointer interface {
+	checkpoint(ctx context.Context, scope *jobScope) error
+}
+
+func (p *Pool[T]) checkpoint(ctx context.Context, scope *jobScope) error {
+	if p.stopped.Load() {
+		return ErrPoolStopped
+	}
+	if ctx.Err() != nil {
+		return causeOf(ctx)
+	}
+	if scope.preemptEvery == 0 {
+		return nil
+	}
+	scope.mu.Lock()
+	scope.checkpoints++
+	due := scope.checkpoints%scope.preemptEvery == 0
+	scope.mu.Unlock()
+	if !due {
+		return nil
+	}
+	// A job the scheduler took waits for a worker too
+	if p.counters.waiting.Load() > 0 {
+		return ErrJobYielded
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.queue) > 0 {
+		return ErrJobYielded
+	}
+	return nil
+}
+
+// Error a job returns to yield its worker and run next later, see
+// WithPreemptiveCheckpoints. A pool without preemptive checkpoints, or of
+// another type than T, fails the job with an error matching ErrJobYielded.
+func Continue[T any](next func(ctx context.Context) (*T, error)) error {
+	return &continuation[T]{next: next}
+}
+
+type continuation[T any] struct {
+	next func(ctx context.Context) (*T, error)
+}
+
+func (c *continuation[T]) Error() string {
+	return ErrJobYielded.Error()
+}
+
+func (c *continuation[T]) Unwrap() error {
+	return ErrJobYielded
+}
+
+// Function a job yielded its worker to run later, nil if it did not
+func continued[T any](err error) func(ctx context.Context) (*T, error) {
+	var c *continuation[T]
+	if errors.As(err, &c) {
+		return c.next
+	}
+	return nil
+}
+
+// Queue a job again if it yielded, and let the scheduler know the job no
+// longer runs. Reports whether the job yielded.
+func (p *Pool[T]) resume(job queuedJob[T]) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.blockedOn--
+	p.queued.Broadcast()
+	next := job.handle.next
+	if next == nil {
+		return false
+	}
+	job.handle.next = nil
+	job.fn, job.plain, job.value = next, nil, nil
+	job.resumed = true
+	job.addedAt = p.clock.Now()
+	scope := &job.handle.scope
+	scope.mu.Lock()
+	scope.done, scope.checkpoints = false, 0
+	scope.mu.Unlock()
+	p.push(job)
+	return true
+}
@@ -0,0 +1,119 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+// Collector counting the jobs that started and ended
+type countingCollector struct {
+	started, ended atomic.Int32
+}
+
+func (c *countingCollector) JobStarted(string)                  { c.started.Add(1) }
+func (c *countingCollector) JobSucceeded(string, time.Duration) { c.ended.Add(1) }
+func (c *countingCollector) JobFailed(string, time.Duration)    { c.ended.Add(1) }
+func (c *countingCollector) JobPanicked(string, time.Duration)  { c.ended.Add(1) }
+
+// Job that passes checkpoints until it is told to yield, then continues
+// with the given value once it runs again
+func yieldingJob(value int, steps *[]string, mu *sync.Mutex, name string) func(ctx context.Context) (*int, error) {
+	log := func(step string) {
+		mu.Lock()
+		defer mu.Unlock()
+		*steps = append(*steps, step)
+	}
+	return func(ctx context.Context) (*int, error) {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			err := typed_goroutine.Checkpoint(ctx)
+			if errors.Is(err, typed_goroutine.ErrJobYielded) {
+				log(name + " yielded")
+				return nil, typed_goroutine.Continue(func(ctx context.Context) (*int, error) {
+					log(name + " resumed")
+					return &value, nil
+				})
+			}
+			if err != nil {
+				return nil, err
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return nil, errors.New("job never yielded")
+	}
+}
+
+func TestCheckpointYieldsToJobWaitingForWorker(t *testing.T) {
+	collector := &countingCollector{}
+	pool := typed_goroutine.NewPool[int](2, 1,
+		typed_goroutine.WithPreemptiveCheckpoints[int](1),
+		typed_goroutine.WithCollector[int](collector),
+	)
+	var mu sync.Mutex
+	var steps []string
+	if _, err := pool.AddJobCtx(yieldingJob(1, &steps, &mu, "long")); err != nil {
+		t.Fatal(err)
+	}
+	// Taken by the scheduler while the long job holds the only worker, so
+	// the queue is empty while it waits
+	if _, err := pool.AddJob(func() (*int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		steps = append(steps, "short")
+		value := 2
+		return &value, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	results, _ := pool.Wait()
+	for _, result := range results {
+		if !result.OK {
+			t.Fatalf("job %d failed: %v", result.Index, result.Error)
+		}
+	}
+	if want := []string{"long yielded", "short", "long resumed"}; !slices.Equal(steps, want) {
+		t.Fatalf("got steps %q, want %q", steps, want)
+	}
+	if results[0].Value != 1 || results[1].Value != 2 {
+		t.Fatalf("got values %d and %d, want 1 and 2", results[0].Value, results[1].Value)
+	}
+	if started, ended := collector.started.Load(), collector.ended.Load(); started != 2 || ended != 2 {
+		t.Fatalf("collector saw %d jobs start and %d end, want 2 each", started, ended)
+	}
+}
+
+func TestCheckpointResumesInYieldOrder(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](3, 1, typed_goroutine.WithPreemptiveCheckpoints[int](1))
+	var mu sync.Mutex
+	var steps []string
+	for i, name := range []string{"first", "second"} {
+		if _, err := pool.AddJobCtx(yieldingJob(i, &steps, &mu, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pool.AddJob(func() (*int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		steps = append(steps, "plain")
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	results, _ := pool.Wait()
+	for _, result := range results {
+		if !result.OK {
+			t.Fatalf("job %d failed: %v", result.Index, result.Error)
+		}
+	}
+	want := []string{"first yielded", "second yielded", "plain", "first resumed", "second resumed"}
+	if !slices.Equal(steps, want) {
+		t.Fatalf("got steps %q, want %q", steps, want)
+	}
+}
@@ -0,0 +1,156 @@
+package typed_goroutine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Create a pool for the work a job of parent fans out, configured like
+// parent: it starts with the workers, logger, clock, panic handler and
+// collector of parent, which opts may override, and the name of parent
+// joined to its own by a slash, so its metrics are told apart. The child
+// runs with ctx by default, usually the context of the job creating it.
+// While parent runs, the context of the child is also cancelled once
+// parent is stopped, drained, cancelled or done, and so is the child.
+func Child[T, U any](ctx context.Context, parent *Pool[T], opts ...Option[U]) (*Pool[U], error) {
+	parent.mu.Lock()
+	logger, level, clock := parent.logger, parent.jobLogLevel, parent.clock
+	panicHandler, collector := parent.panicHandler, parent.collector
+	if parent.active() {
+		// Released once the run of parent ends, which outlasts the child
+		ctx, _ = mergeCancel(ctx, parent.schedulingCtx)
+	}
+	parent.mu.Unlock()
+	inherit := func(p *Pool[U]) error {
+		p.ctx = ctx
+		p.maxWorkers = parent.MaxWorkers()
+		p.logger = logger
+		p.jobLogLevel = level
+		p.clock = clock
+		p.panicHandler = panicHandler
+		p.collector = collector
+		p.parentLimit = newParentLimit(ctx, parent)
+		return nil
+	}
+	child, err := NewPoolWithOptions(append([]Option[U]{inherit}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case parent.name == "":
+	case child.name == "":
+		child.name = parent.name
+	default:
+		child.name = parent.name + "/" + child.name
+	}
+	return child, nil
+}
+
+// Take the weight of the jobs of a pool created with Child from the
+// workers of its parent, so the two never run more jobs at once than the
+// parent has workers. If the context given to Child belongs to a job of
+// the parent, that job lends its worker to the child, it should wait for
+// the child rather than work alongside it. Otherwise a parent whose
+// workers all wait for their children would never start a job of them.
+func WithParentLimit[T any]() Option[T] {
+	return func(p *Pool[T]) error {
+		if p.parentLimit == nil {
+			return fmt.Errorf("%w: only a pool created with Child shares the limit of its parent", ErrInvalidOption)
+		}
+		return p.limitBy(p.parentLimit)
+	}
+}
+
+// Workers of a parent pool as a Limiter for a child pool, with the worker
+// of the job that created the child lent to it
+type parentLimit[T any] struct {
+	parent *Pool[T]
+	mu     sync.Mutex
+	// Worker lent by the job of the parent and how much of it is held,
+	// and the workers held of the parent itself
+	lent, borrowed, taken int64
+	// Closed and replaced once the lent worker is given back, nil while
+	// nobody waits for it
+	freed chan struct{}
+}
+
+func newParentLimit[T any](ctx context.Context, parent *Pool[T]) *parentLimit[T] {
+	l := &parentLimit[T]{parent: parent}
+	if scope, ok := ctx.Value(jobInfoKey{}).(*jobScope); ok && scope.pool == checkpointer(parent) {
+		l.lent = 1
+	}
+	return l
+}
+
+// Take the lent worker if it is free, or otherwise workers of the parent,
+// whichever comes first
+func (l *parentLimit[T]) Acquire(ctx context.Context, n int64) error {
+	for {
+		l.mu.Lock()
+		if l.borrowed+n <= l.lent {
+			l.borrowed += n
+			l.mu.Unlock()
+			return nil
+		}
+		if l.freed == nil {
+			l.freed = make(chan struct{})
+		}
+		freed := l.freed
+		l.mu.Unlock()
+		held, err := l.hold(ctx, n, freed)
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			// The lent worker was given back in the meantime
+			continue
+		}
+		if held < n {
+			l.parent.release(held)
+			return fmt.Errorf("acquiring %d of %d workers", n, held)
+		}
+		l.mu.Lock()
+		l.taken += n
+		l.mu.Unlock()
+		return nil
+	}
+}
+
+// Take n workers of the parent, giving up once freed is closed unless
+// nothing was lent
+func (l *parentLimit[T]) hold(ctx context.Context, n int64, freed <-chan struct{}) (int64, error) {
+	if l.lent == 0 {
+		return l.parent.hold(ctx, n)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-freed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return l.parent.hold(ctx, n)
+}
+
+// Give back the workers of the parent first, so its other jobs can use
+// them, then the lent one. Workers are alike, so it does not matter which
+// of them a job took.
+func (l *parentLimit[T]) Release(n int64) {
+	l.mu.Lock()
+	back := min(n, l.taken)
+	l.taken -= back
+	if n > back {
+		l.borrowed -= n - back
+		if l.freed != nil {
+			close(l.freed)
+			l.freed = nil
+		}
+	}
+	l.mu.Unlock()
+	if back > 0 {
+		l.parent.release(back)
+	}
+}
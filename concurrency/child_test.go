@@ -0,0 +1,115 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+func TestChildInheritsFromParent(t *testing.T) {
+	parent := typed_goroutine.NewPool[int](1, 3, typed_goroutine.WithName[int]("crawl"))
+	child, err := typed_goroutine.Child(context.Background(), parent, typed_goroutine.WithName[string]("fetch"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.Name() != "crawl/fetch" {
+		t.Fatalf("got name %q, want crawl/fetch", child.Name())
+	}
+	if child.MaxWorkers() != 3 {
+		t.Fatalf("got %d workers, want the 3 of the parent", child.MaxWorkers())
+	}
+}
+
+func TestChildStopsWithParent(t *testing.T) {
+	parent := typed_goroutine.NewPool[int](1, 1)
+	started := make(chan struct{})
+	childErr := make(chan error, 1)
+	_, err := parent.AddJobCtx(func(ctx context.Context) (*int, error) {
+		// Created from a context that is never cancelled
+		child, err := typed_goroutine.Child[int, int](context.Background(), parent)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := child.AddJobCtx(func(ctx context.Context) (*int, error) {
+			close(started)
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}); err != nil {
+			return nil, err
+		}
+		results, _ := child.Wait()
+		childErr <- results[0].Error
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := parent.Run(); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	stopCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, _, err := parent.Stop(stopCtx); err != nil {
+		t.Fatalf("parent did not stop: %v", err)
+	}
+	if err := <-childErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("child job ended with %v, want it cancelled", err)
+	}
+}
+
+func TestChildSharesParentLimit(t *testing.T) {
+	parent := typed_goroutine.NewPool[int](1, 2)
+	var running, most atomic.Int32
+	_, err := parent.AddJobCtx(func(ctx context.Context) (*int, error) {
+		child, err := typed_goroutine.Child(ctx, parent, typed_goroutine.WithParentLimit[int]())
+		if err != nil {
+			return nil, err
+		}
+		for range 6 {
+			if _, err := child.AddJob(func() (*int, error) {
+				now := running.Add(1)
+				for {
+					seen := most.Load()
+					if now <= seen || most.CompareAndSwap(seen, now) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				running.Add(-1)
+				return nil, nil
+			}); err != nil {
+				return nil, err
+			}
+		}
+		results, _ := child.Wait()
+		for _, result := range results {
+			if !result.OK {
+				return nil, result.Error
+			}
+		}
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, _ := parent.Wait()
+	if !results[0].OK {
+		t.Fatalf("parent job failed: %v", results[0].Error)
+	}
+	// The lent worker and the other worker of the parent
+	if most.Load() > 2 {
+		t.Fatalf("%d child jobs ran at once, want at most the 2 workers of the parent", most.Load())
+	}
+}
+
+func TestWithParentLimitNeedsChild(t *testing.T) {
+	_, err := typed_goroutine.NewPoolWithOptions(typed_goroutine.WithParentLimit[int]())
+	if !errors.Is(err, typed_goroutine.ErrInvalidOption) {
+		t.Fatalf("got %v, want ErrInvalidOption", err)
+	}
+}
@@ -0,0 +1,145 @@
+package typed_goroutine
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Source of time for a pool, every wait and every measured duration goes
+// through it. Tests may replace it to step time deterministically, see the
+// typedpooltest package.
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+}
+
+// Timer created by a Clock, behaves like a *time.Timer
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Use clock instead of the real time for retries, timeouts, rate limits and
+// the durations and timestamps of results. With a clock other than the
+// real one a job timeout cancels the context of the job with
+// ErrJobTimeout as its cause instead of setting a deadline.
+func WithClock[T any](clock Clock) Option[T] {
+	return func(p *Pool[T]) error {
+		if clock == nil {
+			return fmt.Errorf("%w: nil clock", ErrInvalidOption)
+		}
+		p.clock = clock
+		return nil
+	}
+}
+
+// Clock of the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{time.NewTimer(d)}
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+type realTimer struct {
+	*time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.Timer.C
+}
+
+// Time elapsed since t according to the clock of the pool
+func (p *Pool[T]) since(t time.Time) time.Duration {
+	return p.clock.Now().Sub(t)
+}
+
+// Derive a context that is done after d according to the clock of the pool
+func (p *Pool[T]) withTimeout(ctx context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, p.clock, d)
+}
+
+// Derive a context that is done after d according to clock, with
+// ErrJobTimeout as its cause unless clock is the real one
+func withTimeout(ctx context.Context, clock Clock, d time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := clock.(realClock); ok {
+		return context.WithTimeout(ctx, d)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := clock.NewTimer(d)
+	go func() {
+		select {
+		case <-timer.C():
+			cancel(ErrJobTimeout)
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}()
+	// Stop the timer right away too, so a fake clock no longer counts it
+	// once the caller is done with ctx
+	return ctx, func() {
+		cancel(nil)
+		timer.Stop()
+	}
+}
+
+// Derive a context from ctx that is cancelled with cause at deadline as
+// measured by the clock of the pool
+func (p *Pool[T]) withDeadline(ctx context.Context, deadline time.Time, cause error) (context.Context, context.CancelFunc) {
+	if _, ok := p.clock.(realClock); ok {
+		return context.WithDeadlineCause(ctx, deadline, cause)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	timer := p.clock.NewTimer(deadline.Sub(p.clock.Now()))
+	go func() {
+		select {
+		case <-timer.C():
+			cancel(cause)
+		case <-ctx.Done():
+			timer.Stop()
+		}
+	}()
+	// Stop the timer right away too, so a fake clock no longer counts it
+	// once the caller is done with ctx
+	return ctx, func() {
+		cancel(nil)
+		timer.Stop()
+	}
+}
+
+// Call the slow job hook once the job ran for the threshold, the returned
+// function stops watching and reports whether the hook was called
+func (p *Pool[T]) watchSlow(ctx context.Context, job queuedJob[T], startedAt time.Time) func() bool {
+	timer := p.clock.NewTimer(p.slowThreshold)
+	finished := make(chan struct{})
+	fired := make(chan bool, 1)
+	go func() {
+		select {
+		case <-timer.C():
+			p.callHook(ctx, job, func() { p.onSlow(job.index, job.name, p.since(startedAt)) })
+			fired <- true
+		case <-finished:
+			timer.Stop()
+			fired <- false
+		}
+	}()
+	return func() bool {
+		close(finished)
+		return <-fired
+	}
+}
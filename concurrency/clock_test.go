@@ -0,0 +1,89 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+	"github.com/demy076/typed_goroutines/concurrency/typedpooltest"
+)
+
+func TestTimeoutMiddlewareUsesPoolClock(t *testing.T) {
+	clock := typedpooltest.NewClock(time.Unix(0, 0))
+	pool := typed_goroutine.NewPool[int](1, 1,
+		typed_goroutine.WithClock[int](clock),
+		typed_goroutine.WithMiddleware(typed_goroutine.TimeoutMiddleware[int](time.Minute)),
+	)
+	if _, err := pool.AddJobCtx(func(ctx context.Context) (*int, error) {
+		<-ctx.Done()
+		return nil, context.Cause(ctx)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	results, _ := pool.Wait()
+	if !errors.Is(results[0].Error, typed_goroutine.ErrJobTimeout) {
+		t.Fatalf("got %v, want the attempt to time out by the fake clock", results[0].Error)
+	}
+}
+
+func TestRetryBackoffWaitsForFakeClock(t *testing.T) {
+	clock := typedpooltest.NewClock(time.Unix(0, 0))
+	pool := typed_goroutine.NewPool[int](1, 1,
+		typed_goroutine.WithClock[int](clock),
+		typed_goroutine.WithRetries[int](1, func(int) time.Duration { return time.Hour }),
+	)
+	var attempts atomic.Int32
+	failed := make(chan struct{})
+	if _, err := pool.AddJobV(func() (int, error) {
+		if attempts.Add(1) == 1 {
+			close(failed)
+			return 0, errors.New("flaky")
+		}
+		return 1, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	<-failed
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour - time.Second)
+	if n := attempts.Load(); n != 1 || clock.Timers() != 1 {
+		t.Fatalf("got %d attempts and %d timers before the backoff passed, want 1 and the backoff", n, clock.Timers())
+	}
+	clock.Advance(time.Second)
+	results, _ := pool.Wait()
+	if len(results) != 1 || !results[0].OK || results[0].Attempts != 2 {
+		t.Fatalf("got %+v, want the second attempt to succeed", results)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("backoff of an hour took %v of real time", elapsed)
+	}
+}
+
+func TestLRUCacheExpiresByItsClock(t *testing.T) {
+	clock := typedpooltest.NewClock(time.Unix(0, 0))
+	cache := typed_goroutine.NewLRUCache[int](2, typed_goroutine.LRUCacheClock(clock))
+	// Neither pool the cache is shared with changes the clock it expires by
+	other := typedpooltest.NewClock(time.Unix(0, 0))
+	typed_goroutine.NewPool[int](0, 1, typed_goroutine.WithClock[int](clock), typed_goroutine.WithCache[int](cache))
+	typed_goroutine.NewPool[int](0, 1, typed_goroutine.WithClock[int](other), typed_goroutine.WithCache[int](cache))
+	cache.Set("key", typed_goroutine.Result[int]{Value: 1, OK: true}, time.Minute)
+	if _, ok := cache.Get("key"); !ok {
+		t.Fatal("result expired before its time")
+	}
+	clock.Advance(time.Minute)
+	if _, ok := cache.Get("key"); ok {
+		t.Fatal("result did not expire once the fake clock passed its ttl")
+	}
+}
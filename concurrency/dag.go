@@ -0,0 +1,174 @@
+package typed_goroutine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// A job that waits for the jobs it depends on, guarded by the lock of the
+// pool
+type waitingJob[T any] struct {
+	job queuedJob[T]
+	// Dependencies that have not succeeded yet
+	remaining int
+	settled   bool
+}
+
+// A job that can never run because of its dependencies
+type blockedJob[T any] struct {
+	job queuedJob[T]
+	err error
+}
+
+// Add a job that only starts once every job in deps succeeded, deps are the
+// indexes of other jobs of the pool as returned by JobHandle.Index. They may
+// refer to jobs added later, the dependencies are resolved when the pool
+// starts. If a dependency fails, panics or is skipped, the job is skipped
+// with ErrDependencyFailed. Must be added before the pool starts.
+func (p *Pool[T]) AddDependentJob(job func() (*T, error), deps ...int) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{plain: job, deps: deps}, false)
+}
+
+// Resolve the dependencies of the waiting jobs when the pool starts, the
+// caller must hold the lock of the pool. Returns the jobs that can never
+// run together with an error describing every unknown dependency and cycle.
+func (p *Pool[T]) resolveDependencies() ([]blockedJob[T], error) {
+	if len(p.waiting) == 0 {
+		return nil, nil
+	}
+	jobs := make(map[int]*waitingJob[T], len(p.waiting))
+	for i := range p.waiting {
+		jobs[p.waiting[i].job.index] = &p.waiting[i]
+	}
+	blocked := make(map[int]error)
+	var errs []error
+	for i := range p.waiting {
+		job := p.waiting[i].job
+		for _, dep := range job.deps {
+			if !p.hasIndex(dep) {
+				err := fmt.Errorf("%w: job %d depends on job %d", ErrUnknownDependency, job.index, dep)
+				blocked[job.index] = err
+				errs = append(errs, err)
+				break
+			}
+		}
+	}
+	for _, cycle := range findCycles(p.waiting, jobs) {
+		names := make([]string, 0, len(cycle)+1)
+		for _, index := range cycle {
+			names = append(names, fmt.Sprintf("job %d", index))
+		}
+		names = append(names, fmt.Sprintf("job %d", cycle[0]))
+		err := fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(names, " -> "))
+		for _, index := range cycle {
+			if _, ok := blocked[index]; !ok {
+				blocked[index] = err
+			}
+		}
+		errs = append(errs, err)
+	}
+	p.dependents = make(map[int][]*waitingJob[T])
+	var skips []blockedJob[T]
+	for i := range p.waiting {
+		waiting := &p.waiting[i]
+		if err, ok := blocked[waiting.job.index]; ok {
+			waiting.settled = true
+			skips = append(skips, blockedJob[T]{job: waiting.job, err: err})
+			continue
+		}
+		seen := make(map[int]bool, len(waiting.job.deps))
+		for _, dep := range waiting.job.deps {
+			if seen[dep] {
+				continue
+			}
+			seen[dep] = true
+			waiting.remaining++
+			p.dependents[dep] = append(p.dependents[dep], waiting)
+		}
+		p.blockedOn++
+	}
+	return skips, errors.Join(errs...)
+}
+
+// Cycles among the waiting jobs, each as the indexes of the jobs in it
+func findCycles[T any](waiting []waitingJob[T], jobs map[int]*waitingJob[T]) [][]int {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[int]int, len(waiting))
+	var stack []int
+	var cycles [][]int
+	var visit func(index int)
+	visit = func(index int) {
+		state[index] = visiting
+		stack = append(stack, index)
+		for _, dep := range jobs[index].job.deps {
+			if _, ok := jobs[dep]; !ok {
+				continue
+			}
+			switch state[dep] {
+			case unvisited:
+				visit(dep)
+			case visiting:
+				// The part of the stack from dep onwards is a cycle
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i] == dep {
+						cycles = append(cycles, append([]int(nil), stack[i:]...))
+						break
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[index] = visited
+	}
+	for i := range waiting {
+		if state[waiting[i].job.index] == unvisited {
+			visit(waiting[i].job.index)
+		}
+	}
+	return cycles
+}
+
+// Release or skip the jobs that depend on a job once it completed
+func (p *Pool[T]) settle(job queuedJob[T], result Result[T]) {
+	if !p.hasDependencies {
+		return
+	}
+	ok := result.Error == nil && result.Panic == nil
+	var skips []queuedJob[T]
+	p.mu.Lock()
+	for _, waiting := range p.dependents[job.index] {
+		if waiting.settled {
+			continue
+		}
+		if !ok {
+			waiting.settled = true
+			skips = append(skips, waiting.job)
+			p.blockedOn--
+			continue
+		}
+		waiting.remaining--
+		if waiting.remaining == 0 {
+			waiting.settled = true
+			p.push(waiting.job)
+			p.blockedOn--
+		}
+	}
+	delete(p.dependents, job.index)
+	p.queued.Broadcast()
+	p.mu.Unlock()
+	for _, skipped := range skips {
+		p.skip(skipped, fmt.Errorf("%w: %s", ErrDependencyFailed, jobLabel(job.index, job.name)))
+	}
+}
+
+// Skip the jobs that can never run because of their dependencies
+func (p *Pool[T]) skipBlocked(blocked []blockedJob[T]) {
+	for _, b := range blocked {
+		p.skip(b.job, b.err)
+	}
+}
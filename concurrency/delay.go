@@ -0,0 +1,144 @@
+package typed_goroutine
+
+import (
+	"context"
+	"time"
+)
+
+// Add a job that is only queued once d passed since the pool started,
+// without taking a worker while it waits. Wait returns once it ran, and
+// stopping the pool or cancelling its context skips the jobs still waiting
+// right away. Delays are measured by the clock set WithClock.
+func (p *Pool[T]) AddJobAfter(d time.Duration, job func() (*T, error)) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{plain: job, delay: d}, false)
+}
+
+// Submit a job that is queued once d passed since it was submitted to a
+// running pool, or since the pool started if it was submitted before, see
+// AddJobAfter and Submit
+func (p *Pool[T]) SubmitAfter(d time.Duration, job func() (*T, error)) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{plain: job, delay: d}, true)
+}
+
+// Jobs waiting for their delay ordered by when they are due, then by index
+type delayQueue[T any] struct {
+	jobQueue[T]
+}
+
+func dueBefore[T any](q jobQueue[T], i, j int) bool {
+	// Held jobs are never due
+	if q[i].held != q[j].held {
+		return q[j].held
+	}
+	if !q[i].due.Equal(q[j].due) {
+		return q[i].due.Before(q[j].due)
+	}
+	return q[i].index < q[j].index
+}
+
+func (q *delayQueue[T]) push(job queuedJob[T]) {
+	q.insert(job, dueBefore[T])
+}
+
+func (q *delayQueue[T]) pop() queuedJob[T] {
+	return q.removeAt(0, dueBefore[T])
+}
+
+func (q *delayQueue[T]) remove(pos int) queuedJob[T] {
+	return q.removeAt(pos, dueBefore[T])
+}
+
+// Hold back a delayed job, due is set once the pool runs. The caller must
+// hold the lock of the pool.
+func (p *Pool[T]) delayJob(job queuedJob[T]) {
+	if p.running {
+		job.due = job.addedAt.Add(job.delay)
+	}
+	job.handle.delayed = true
+	p.delays.push(job)
+	p.blockedOn++
+	p.wakeDelays()
+}
+
+// Fix when the jobs delayed before the pool started are due, the caller
+// must hold the lock of the pool
+func (p *Pool[T]) scheduleDelays() {
+	for i := range p.delays.jobQueue {
+		p.delays.jobQueue[i].due = p.startedAt.Add(p.delays.jobQueue[i].delay)
+	}
+	p.delays.init(dueBefore[T])
+}
+
+// Let the delay goroutine know that the delays or the pool changed, the
+// caller must hold the lock of the pool
+func (p *Pool[T]) wakeDelays() {
+	select {
+	case p.delayWake <- struct{}{}:
+	default:
+	}
+}
+
+// Queue delayed jobs once they are due until the pool is closed and no job
+// is delayed any more, skipping the delayed jobs once ctx is done
+func (p *Pool[T]) releaseDelays(ctx context.Context, wake <-chan struct{}) {
+	defer p.delayGroup.Done()
+	for {
+		p.mu.Lock()
+		now := p.clock.Now()
+		for len(p.delays.jobQueue) > 0 && p.due(now) {
+			job := p.delays.pop()
+			job.handle.delayed = false
+			p.push(job)
+			p.blockedOn--
+		}
+		p.queued.Broadcast()
+		if len(p.delays.jobQueue) == 0 && p.closed {
+			p.mu.Unlock()
+			return
+		}
+		var due <-chan time.Time
+		var timer Timer
+		if len(p.delays.jobQueue) > 0 && !p.delays.jobQueue[0].held {
+			timer = p.clock.NewTimer(p.delays.jobQueue[0].due.Sub(now))
+			due = timer.C()
+		}
+		p.mu.Unlock()
+		select {
+		case <-due:
+		case <-wake:
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			p.skipDelays(ctx)
+			return
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+// Whether the first delayed job is due by now, the caller must hold the
+// lock of the pool
+func (p *Pool[T]) due(now time.Time) bool {
+	first := &p.delays.jobQueue[0]
+	return !first.held && !first.due.After(now)
+}
+
+// Skip every job still waiting for its delay
+func (p *Pool[T]) skipDelays(ctx context.Context) {
+	p.mu.Lock()
+	var jobs []queuedJob[T]
+	for len(p.delays.jobQueue) > 0 {
+		job := p.delays.pop()
+		job.handle.delayed = false
+		jobs = append(jobs, job)
+	}
+	p.blockedOn -= len(jobs)
+	p.queued.Broadcast()
+	p.mu.Unlock()
+	for _, job := range jobs {
+		p.skip(job, p.skipped(ctx))
+	}
+}
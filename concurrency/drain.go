@@ -0,0 +1,79 @@
+package typed_goroutine
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// Error returned by Drain when running jobs did not return in time
+type AbandonedError struct {
+	// Jobs that had not finished when Drain gave up on them
+	Jobs int
+	// Error of the context passed to Drain
+	Err error
+}
+
+func (e *AbandonedError) Error() string {
+	return fmt.Sprintf("%d jobs abandoned: %v", e.Jobs, e.Err)
+}
+
+func (e *AbandonedError) Unwrap() error {
+	return e.Err
+}
+
+// Stop starting jobs, cancel the context of the running ones with
+// ErrPoolDrained and wait for them to return until ctx is done. Returns the
+// results and panics collected by then, including the jobs that never
+// started with ErrPoolStopped. If ctx is done first the error is an
+// *AbandonedError counting the jobs still running, their results are
+// dropped. Either way the pool is done afterwards and Wait returns the same
+// values, as does draining again. Draining a pool that is done returns what
+// Wait returns.
+func (p *Pool[T]) Drain(ctx context.Context) ([]Result[T], []PanicInfo, error) {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return nil, nil, ErrNotRunning
+	}
+	if p.state() != PoolDone {
+		p.stopped.Store(true)
+		p.stopScheduling()
+		p.closed = true
+		p.queued.Broadcast()
+		p.dequeued.Broadcast()
+		p.cancel(ErrPoolDrained)
+	}
+	done := p.done
+	p.mu.Unlock()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		p.abandon(ctx.Err())
+	}
+	results, panics := p.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return results, panics, p.drainErr
+}
+
+// Mark the pool as done while jobs are still running, keeping only the
+// results collected so far
+func (p *Pool[T]) abandon(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	select {
+	case <-p.done:
+		// The last job returned in the meantime
+		return
+	default:
+	}
+	p.abandoned = true
+	sortResults(p.results)
+	sort.Slice(p.panics, func(i, j int) bool {
+		return p.panics[i].Index < p.panics[j].Index
+	})
+	p.sorted = true
+	p.drainErr = &AbandonedError{Jobs: p.added - p.finished, Err: err}
+	close(p.done)
+}
@@ -0,0 +1,47 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+func TestDrainDropsStreamedResultsOfAbandonedJobs(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](2, 1)
+	// Never read, its buffer fills up with the result of the first job
+	_ = pool.Results()
+	if _, err := pool.AddJob(func() (*int, error) { return nil, nil }); err != nil {
+		t.Fatal(err)
+	}
+	started, release := make(chan struct{}), make(chan struct{})
+	if _, err := pool.AddJob(func() (*int, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, _, err := pool.Drain(ctx)
+	var abandoned *typed_goroutine.AbandonedError
+	if !errors.As(err, &abandoned) || abandoned.Jobs != 1 {
+		t.Fatalf("got %v, want one abandoned job", err)
+	}
+	close(release)
+	// The worker exits once the result of the abandoned job was dropped
+	deadline := time.Now().Add(5 * time.Second)
+	for pool.Reset() != nil {
+		if time.Now().After(deadline) {
+			t.Fatal("worker of the abandoned job never exited")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
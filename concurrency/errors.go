@@ -0,0 +1,81 @@
+package typed_goroutine
+
+import "sync"
+
+// Stream the error of every job that fails or is skipped as soon as it is
+// recorded, each a *JobError, the channel is closed once the pool is done.
+// Panics only arrive when they are converted to errors WithPanicsAsErrors.
+// Errors are also returned by Wait as usual. Must be called before Run,
+// afterwards it returns a closed channel. The errors are buffered, so the
+// pool never blocks on a subscriber that does not keep up, but the channel
+// must be drained until it is closed to let its goroutine exit.
+func (p *Pool[T]) Errors() <-chan error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.errorFeed == nil {
+		if p.running {
+			closed := make(chan error)
+			close(closed)
+			return closed
+		}
+		p.errorFeed = newErrorFeed()
+	}
+	return p.errorFeed.out
+}
+
+// Unbounded buffer of errors forwarded to a channel by its own goroutine
+type errorFeed struct {
+	out chan error
+	// Wakes up the forwarding goroutine
+	ready   chan struct{}
+	mu      sync.Mutex
+	pending []error
+	closed  bool
+}
+
+func newErrorFeed() *errorFeed {
+	return &errorFeed{out: make(chan error), ready: make(chan struct{}, 1)}
+}
+
+// Queue an error without blocking
+func (f *errorFeed) push(err error) {
+	f.mu.Lock()
+	f.pending = append(f.pending, err)
+	f.mu.Unlock()
+	f.wake()
+}
+
+// Close the channel once the queued errors were delivered
+func (f *errorFeed) close() {
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+	f.wake()
+}
+
+func (f *errorFeed) wake() {
+	select {
+	case f.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Forward the queued errors until the feed is closed and drained
+func (f *errorFeed) run() {
+	defer close(f.out)
+	for {
+		f.mu.Lock()
+		pending, closed := f.pending, f.closed
+		f.pending = nil
+		f.mu.Unlock()
+		for _, err := range pending {
+			f.out <- err
+		}
+		if closed && len(pending) == 0 {
+			return
+		}
+		if len(pending) == 0 {
+			<-f.ready
+		}
+	}
+}
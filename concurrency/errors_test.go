@@ -0,0 +1,69 @@
+package typed_goroutine_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+func TestErrorsArriveBeforeWaitReturns(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](3, 2, typed_goroutine.WithPanicsAsErrors[int]())
+	errFetch := errors.New("fetch failed")
+	release := make(chan struct{})
+	if _, err := pool.AddJob(func() (*int, error) { return nil, errFetch }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.AddJob(func() (*int, error) {
+		<-release
+		panic("boom")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.AddJob(noop); err != nil {
+		t.Fatal(err)
+	}
+	errs := pool.Errors()
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	var jobErr *typed_goroutine.JobError
+	select {
+	case err := <-errs:
+		if !errors.As(err, &jobErr) || jobErr.Index != 0 || !errors.Is(err, errFetch) {
+			t.Fatalf("got %v, want the error of job 0", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("error did not arrive while the pool was running")
+	}
+	// Job 1 has not returned yet, so neither has Wait
+	if state := pool.State(); state != typed_goroutine.PoolRunning {
+		t.Fatalf("pool is %v once the first error arrived, want it running", state)
+	}
+	close(release)
+	var panicErr *typed_goroutine.PanicError
+	if err := <-errs; !errors.As(err, &jobErr) || jobErr.Index != 1 || !errors.As(err, &panicErr) {
+		t.Fatalf("got %v, want the panic of job 1 as an error", err)
+	}
+	if err, ok := <-errs; ok {
+		t.Fatalf("got %v, want the channel closed once the pool is done", err)
+	}
+	if results, _ := pool.Wait(); len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+}
+
+func TestErrorsAfterRunIsClosed(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](1, 1)
+	if _, err := pool.AddJob(noop); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-pool.Errors(); ok {
+		t.Fatal("got an error from a channel subscribed after Run, want it closed")
+	}
+	pool.Wait()
+}
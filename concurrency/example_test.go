@@ -0,0 +1,104 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+func ExampleNewPool() {
+	pool := typed_goroutine.NewPool[int](3, 2)
+	for _, n := range []int{1, 2, 3} {
+		pool.AddJob(func() (*int, error) {
+			if n == 2 {
+				return nil, errors.New("two is not allowed")
+			}
+			square := n * n
+			return &square, nil
+		})
+	}
+	results, panics := pool.Wait()
+	for _, result := range results {
+		if result.OK {
+			fmt.Printf("job %d: %d\n", result.Index, result.Value)
+		} else {
+			fmt.Printf("job %d failed: %v\n", result.Index, result.Error)
+		}
+	}
+	fmt.Println("panics:", len(panics))
+	// Output:
+	// job 0: 1
+	// job 1 failed: job 1: two is not allowed
+	// job 2: 9
+	// panics: 0
+}
+
+func ExampleMap() {
+	lengths, err := typed_goroutine.Map(context.Background(), []string{"a", "bb", "", "dddd"}, 2,
+		func(ctx context.Context, s string) (int, error) {
+			if s == "" {
+				return 0, errors.New("empty")
+			}
+			return len(s), nil
+		})
+	fmt.Println(lengths)
+	fmt.Println(err)
+	// Output:
+	// [1 2 0 4]
+	// item 2: empty
+}
+
+func ExampleReduce() {
+	pool := typed_goroutine.NewPool[int](4, 2)
+	for _, n := range []int{1, 2, 3, 4} {
+		pool.AddJobV(func() (int, error) {
+			if n == 3 {
+				return 0, errors.New("three is not allowed")
+			}
+			return n, nil
+		})
+	}
+	results, _ := pool.Wait()
+	total := typed_goroutine.Reduce(results, 0, func(sum int, r typed_goroutine.Result[int]) int {
+		if !r.OK {
+			return sum
+		}
+		return sum + r.Value
+	})
+	fmt.Println("total:", total)
+	// Output:
+	// total: 7
+}
+
+func ExampleReduceStream() {
+	pool := typed_goroutine.NewPool[int](4, 2)
+	for _, n := range []int{1, 2, 3, 4} {
+		pool.AddJobV(func() (int, error) {
+			if n%2 == 0 {
+				return 0, fmt.Errorf("%d is even", n)
+			}
+			return n, nil
+		})
+	}
+	errs, err := typed_goroutine.ReduceStream(pool, nil, func(errs []error, r typed_goroutine.Result[int]) []error {
+		if r.Error != nil {
+			errs = append(errs, r.Error)
+		}
+		return errs
+	})
+	if err != nil {
+		panic(err)
+	}
+	// Jobs finish in any order
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	// Output:
+	// job 1: 2 is even
+	// job 3: 4 is even
+}
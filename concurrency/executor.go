@@ -0,0 +1,62 @@
+package typed_goroutine
+
+import "context"
+
+// What code running jobs needs from a pool, so it can depend on an
+// interface and swap in typedpooltest.FakeExecutor in tests
+type Executor[T any] interface {
+	// Add a job that receives the run context
+	Add(job func(ctx context.Context) (*T, error)) error
+	// Run the jobs unless they run already, wait for them and return
+	// every result in job order, panics as results with Panic set
+	Await(ctx context.Context) ([]Result[T], error)
+}
+
+var _ Executor[int] = (*Pool[int])(nil)
+
+// Add a job that receives the run context like AddJobCtx, or submit it
+// like Submit while a pool created WithDynamicSubmission runs
+func (p *Pool[T]) Add(job func(ctx context.Context) (*T, error)) error {
+	_, err := p.add(queuedJob[T]{fn: job}, true)
+	return err
+}
+
+// Wait for the pool to finish like WaitWithContext and return all results
+// in job order. If ctx is done
+// first the results collected so far are returned with ctx.Err(), the
+// jobs keep running.
+func (p *Pool[T]) Await(ctx context.Context) ([]Result[T], error) {
+	p.start(ctx)
+	select {
+	case <-p.doneChan():
+	case <-ctx.Done():
+		return p.collected(), ctx.Err()
+	}
+	results, _ := p.WaitWithContext(ctx)
+	return results, nil
+}
+
+// Run a single job on the current goroutine once a worker is free, with
+// the retries, timeout, middleware, hooks and panic handling of the pool,
+// whether the pool runs or not. The job shares the limit on the jobs
+// running at the same time with the jobs of the pool, but is not one of
+// them: its result has index -1 and is neither recorded nor counted in
+// Stats, and its failure does not trip WithFailFast or WithFirstError. The
+// current goroutine is numbered like a worker for the job, with a number
+// no worker running at the same time has. If ctx is done before a worker
+// is free the job is skipped with ctx.Err().
+func (p *Pool[T]) Execute(ctx context.Context, job func() (*T, error)) Result[T] {
+	single := queuedJob[T]{index: -1, name: "execute", weight: 1, plain: job, single: true}
+	single.handle = newJobHandle(p, -1)
+	single.addedAt = p.clock.Now()
+	held, err := p.hold(ctx, 1)
+	if err != nil {
+		return Result[T]{Error: wrapError(err, single, 0), Index: -1, Name: single.name, Skipped: true, Meta: Meta{Worker: -1}}
+	}
+	defer p.release(held)
+	p.mu.Lock()
+	worker := p.nextWorkerID
+	p.nextWorkerID++
+	p.mu.Unlock()
+	return p.runJob(ctx, single, worker)
+}
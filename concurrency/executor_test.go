@@ -0,0 +1,43 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+func TestExecuteNumbersItsWorker(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](0, 2)
+	seen := make(map[int]bool)
+	for i := range 3 {
+		result := pool.Execute(context.Background(), func() (*int, error) { return &i, nil })
+		if !result.OK || result.Value != i || result.Index != -1 {
+			t.Fatalf("got %+v, want value %d at index -1", result, i)
+		}
+		if result.Worker < 0 || seen[result.Worker] {
+			t.Fatalf("execution %d ran on worker %d, want a worker of its own", i, result.Worker)
+		}
+		seen[result.Worker] = true
+	}
+}
+
+func TestExecuteSkipsOnceContextDone(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](0, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	// Holds the only worker
+	go pool.Execute(context.Background(), func() (*int, error) {
+		close(started)
+		<-release
+		return nil, nil
+	})
+	<-started
+	result := pool.Execute(ctx, func() (*int, error) { return nil, nil })
+	if !result.Skipped || !errors.Is(result.Error, context.Canceled) {
+		t.Fatalf("got %+v, want the job skipped with context.Canceled", result)
+	}
+}
@@ -0,0 +1,120 @@
+// Package expvarmetrics publishes the metrics of typed_goroutine pools as
+// expvar variables, served as JSON on /debug/vars:
+//
+//	collector := expvarmetrics.New("pools")
+//	pool := typed_goroutine.NewPool[int](0, 4,
+//		typed_goroutine.WithName[int]("crawler"),
+//		typed_goroutine.WithCollector[int](collector),
+//	)
+//
+// Every pool gets a map under the name of the collector, keyed by the name
+// of the pool, holding the counters started, succeeded, failed and
+// panicked, the gauge in_flight, and a histogram of the job durations in
+// duration_ms.
+package expvarmetrics
+
+import (
+	"expvar"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Upper bounds of the duration buckets in milliseconds, longer jobs go to
+// the inf bucket
+var buckets = []int64{1, 5, 10, 50, 100, 500, 1000, 5000, 10000, 60000}
+
+// Collector publishing the metrics of pools under a single expvar map
+type Collector struct {
+	vars *expvar.Map
+	// Guards pools
+	mu    sync.Mutex
+	pools map[string]*poolVars
+}
+
+// Metrics of a single pool
+type poolVars struct {
+	started   expvar.Int
+	succeeded expvar.Int
+	failed    expvar.Int
+	panicked  expvar.Int
+	inFlight  expvar.Int
+	sum       expvar.Int
+	buckets   []*expvar.Int
+}
+
+// Create a collector publishing its metrics under name, panics like expvar
+// does if name is already published
+func New(name string) *Collector {
+	return &Collector{vars: expvar.NewMap(name), pools: make(map[string]*poolVars)}
+}
+
+// Metrics of the named pool, published on first use. Unnamed pools share
+// the default entry.
+func (c *Collector) pool(name string) *poolVars {
+	if name == "" {
+		name = "default"
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if vars, ok := c.pools[name]; ok {
+		return vars
+	}
+	vars := &poolVars{buckets: make([]*expvar.Int, len(buckets)+1)}
+	durations := new(expvar.Map)
+	for i, bound := range buckets {
+		vars.buckets[i] = new(expvar.Int)
+		durations.Set("le_"+strconv.FormatInt(bound, 10), vars.buckets[i])
+	}
+	vars.buckets[len(buckets)] = new(expvar.Int)
+	durations.Set("inf", vars.buckets[len(buckets)])
+	durations.Set("sum", &vars.sum)
+	m := new(expvar.Map)
+	m.Set("started", &vars.started)
+	m.Set("succeeded", &vars.succeeded)
+	m.Set("failed", &vars.failed)
+	m.Set("panicked", &vars.panicked)
+	m.Set("in_flight", &vars.inFlight)
+	m.Set("duration_ms", durations)
+	c.vars.Set(name, m)
+	c.pools[name] = vars
+	return vars
+}
+
+// Count a job that ended and record its duration
+func (c *Collector) end(vars *poolVars, duration time.Duration) {
+	vars.inFlight.Add(-1)
+	ms := duration.Milliseconds()
+	vars.sum.Add(ms)
+	for i, bound := range buckets {
+		if ms <= bound {
+			vars.buckets[i].Add(1)
+			return
+		}
+	}
+	vars.buckets[len(buckets)].Add(1)
+}
+
+func (c *Collector) JobStarted(pool string) {
+	vars := c.pool(pool)
+	vars.started.Add(1)
+	vars.inFlight.Add(1)
+}
+
+func (c *Collector) JobSucceeded(pool string, duration time.Duration) {
+	vars := c.pool(pool)
+	vars.succeeded.Add(1)
+	c.end(vars, duration)
+}
+
+func (c *Collector) JobFailed(pool string, duration time.Duration) {
+	vars := c.pool(pool)
+	vars.failed.Add(1)
+	c.end(vars, duration)
+}
+
+func (c *Collector) JobPanicked(pool string, duration time.Duration) {
+	vars := c.pool(pool)
+	vars.panicked.Add(1)
+	c.end(vars, duration)
+}
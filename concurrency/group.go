@@ -0,0 +1,106 @@
+package typed_goroutine
+
+import (
+	"context"
+	"fmt"
+)
+
+// Add a job to a named group, so the jobs of the group can be waited on and
+// their results collected apart from the rest of the pool
+func (p *Pool[T]) AddJobToGroup(group string, job func() (*T, error)) (*JobHandle[T], error) {
+	if group == "" {
+		return nil, fmt.Errorf("%w: empty group name", ErrUnknownGroup)
+	}
+	return p.add(queuedJob[T]{plain: job, group: group}, false)
+}
+
+// Wait for every job of group to finish or ctx to be done, running the pool
+// first if it was not started yet. The pool then also runs with ctx, so the
+// jobs not started once ctx is done are skipped with its error, like
+// RunWithContext. The results are ordered by job index and
+// include skipped jobs and panics, the latter with Panic set, so there is
+// one result for every job of the group. Returns ErrUnknownGroup if no job
+// was added to group.
+func (p *Pool[T]) WaitGroup(ctx context.Context, group string) ([]Result[T], error) {
+	p.mu.Lock()
+	handles, ok := p.groups[group]
+	p.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownGroup, group)
+	}
+	// Derived from ctx so the jobs are skipped as soon as it is done
+	runCtx, stop := mergeCancel(ctx, p.ctx)
+	if started, _ := p.start(runCtx); started {
+		p.mu.Lock()
+		exited := p.exited
+		p.mu.Unlock()
+		go func() {
+			<-exited
+			stop()
+		}()
+	} else {
+		stop()
+	}
+	results := make([]Result[T], 0, len(handles))
+	for _, handle := range handles {
+		result, err := handle.Await(ctx)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// Results of the finished jobs of every group, ordered by job index. Like
+// WaitGroup this includes skipped jobs and panics, jobs that did not finish
+// yet are left out.
+func (p *Pool[T]) ResultsByGroup() map[string][]Result[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	groups := make(map[string][]Result[T], len(p.groups))
+	for group, handles := range p.groups {
+		var results []Result[T]
+		for _, handle := range handles {
+			if result, ok := handle.Result(); ok {
+				results = append(results, result)
+			}
+		}
+		sortResults(results)
+		groups[group] = results
+	}
+	return groups
+}
+
+// Start the jobs of the groups in turn rather than in the order they were
+// added if byGroup is set, so a group with many jobs cannot hold up the
+// others. Jobs without a group form a group of their own. Within a group
+// jobs start in order, and a higher priority still goes first. A group
+// that gets jobs again after it had none, including a new one, takes its
+// turn from the current round on instead of catching up.
+func WithFairness[T any](byGroup bool) Option[T] {
+	return func(p *Pool[T]) error {
+		p.fair = byGroup
+		return nil
+	}
+}
+
+// Queue a job for the scheduler, placing it in the next round of its group
+// WithFairness. The caller must hold the lock of the pool.
+func (p *Pool[T]) push(job queuedJob[T]) {
+	if p.fair {
+		if p.rounds == nil {
+			p.rounds = make(map[string]int)
+		}
+		job.round = max(p.rounds[job.group], p.round)
+		p.rounds[job.group] = job.round + 1
+	}
+	job.order = p.orderOf(job.index)
+	if job.resumed {
+		// Behind every job waiting at the same priority and the jobs that
+		// yielded before
+		job.order = p.yields
+		p.yields++
+	}
+	p.queue.push(job)
+}
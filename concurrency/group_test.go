@@ -0,0 +1,85 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+func TestWaitGroupCollectsGroup(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](3, 2)
+	for i, group := range []string{"a", "b", "a"} {
+		if _, err := pool.AddJobToGroup(group, func() (*int, error) { return &i, nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, err := pool.WaitGroup(context.Background(), "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Value != 0 || results[1].Value != 2 {
+		t.Fatalf("got %+v, want the results of jobs 0 and 2", results)
+	}
+	if _, err := pool.WaitGroup(context.Background(), "c"); !errors.Is(err, typed_goroutine.ErrUnknownGroup) {
+		t.Fatalf("got %v, want ErrUnknownGroup", err)
+	}
+}
+
+func TestWaitGroupRunsWithItsContext(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](2, 1)
+	started, release := make(chan struct{}), make(chan struct{})
+	if _, err := pool.AddJobToGroup("a", func() (*int, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.AddJobToGroup("a", func() (*int, error) { return nil, nil }); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	if _, err := pool.WaitGroup(ctx, "a"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	close(release)
+	results, _ := pool.Wait()
+	if !results[1].Skipped || !errors.Is(results[1].Error, context.Canceled) {
+		t.Fatalf("got %+v, want the second job skipped once ctx was cancelled", results[1])
+	}
+}
+
+func TestFairnessInterleavesGroups(t *testing.T) {
+	// A single worker starts the jobs in the order they are dispatched
+	pool := typed_goroutine.NewPool[int](23, 1, typed_goroutine.WithFairness[int](true))
+	var starts []string
+	add := func(group string, n int) {
+		for range n {
+			if _, err := pool.AddJobToGroup(group, func() (*int, error) {
+				starts = append(starts, group)
+				return nil, nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	add("big", 20)
+	add("small", 3)
+	pool.Wait()
+	// Strictly in order the small group would only start after the 20 jobs
+	// of the big one
+	want := []string{"big", "small", "big", "small", "big", "small"}
+	for range 17 {
+		want = append(want, "big")
+	}
+	if !reflect.DeepEqual(starts, want) {
+		t.Fatalf("started %v, want %v", starts, want)
+	}
+}
@@ -0,0 +1,129 @@
+package typed_goroutine
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// States of a job, a queued job is either started, which includes being
+// skipped, or cancelled
+const (
+	jobQueued int32 = iota
+	jobStarted
+	jobCancelled
+)
+
+// Handle to a single job added to a pool
+type JobHandle[T any] struct {
+	index   int
+	pool    *Pool[T]
+	state   atomic.Int32
+	done    chan struct{}
+	result  Result[T]
+	skipped bool
+	// Position in the queue of the pool, -1 once taken off, and whether
+	// that is the queue of delayed jobs. Guarded by the lock of the pool.
+	pos     int
+	delayed bool
+	// Carried by the context of the job while it runs
+	scope jobScope
+	// Function the job yielded its worker to run next, see Continue
+	next func(ctx context.Context) (*T, error)
+}
+
+func newJobHandle[T any](p *Pool[T], index int) *JobHandle[T] {
+	return &JobHandle[T]{index: index, pool: p, done: make(chan struct{}), pos: -1}
+}
+
+// Position of the job in the order it was added to the pool
+func (h *JobHandle[T]) Index() int {
+	return h.index
+}
+
+// Closed once the job finished, panicked or was skipped
+func (h *JobHandle[T]) Done() <-chan struct{} {
+	return h.done
+}
+
+// Wait for the job to finish or ctx to be done
+func (h *JobHandle[T]) Await(ctx context.Context) (Result[T], error) {
+	select {
+	case <-h.done:
+		return h.result, nil
+	case <-ctx.Done():
+		return Result[T]{}, ctx.Err()
+	}
+}
+
+// Result of the job, reports false while the job has not finished yet
+func (h *JobHandle[T]) Result() (Result[T], bool) {
+	select {
+	case <-h.done:
+		return h.result, true
+	default:
+		return Result[T]{}, false
+	}
+}
+
+// Whether the job panicked, a panic is reported through Result.Panic or
+// as a *PanicError when panics are converted to errors
+func (h *JobHandle[T]) Panicked() bool {
+	select {
+	case <-h.done:
+	default:
+		return false
+	}
+	if h.result.Panic != nil {
+		return true
+	}
+	return isPanicError(h.result.Error)
+}
+
+// Whether the job was never started because it or the pool was cancelled,
+// or the pool failed fast
+func (h *JobHandle[T]) Skipped() bool {
+	select {
+	case <-h.done:
+		return h.skipped
+	default:
+		return false
+	}
+}
+
+// Cancel the job if it has not started yet, it is then reported with
+// ErrJobCancelled. Returns false if the job already started or finished.
+func (h *JobHandle[T]) Cancel() bool {
+	if !h.state.CompareAndSwap(jobQueued, jobCancelled) {
+		return false
+	}
+	h.pool.mu.Lock()
+	if h.pos < 0 {
+		// The scheduler took the job and reports it once it notices
+		h.pool.mu.Unlock()
+		return true
+	}
+	var job queuedJob[T]
+	if h.delayed {
+		job = h.pool.delays.remove(h.pos)
+		h.delayed = false
+		h.pool.blockedOn--
+		h.pool.queued.Broadcast()
+	} else {
+		job = h.pool.queue.remove(h.pos)
+	}
+	h.pool.mu.Unlock()
+	h.pool.skip(job, ErrJobCancelled)
+	return true
+}
+
+// Mark the job as started, reports false if it was cancelled
+func (h *JobHandle[T]) start() bool {
+	return h.state.CompareAndSwap(jobQueued, jobStarted)
+}
+
+// Record the outcome of the job, called exactly once per job
+func (h *JobHandle[T]) complete(result Result[T], skipped bool) {
+	h.result = result
+	h.skipped = skipped
+	close(h.done)
+}
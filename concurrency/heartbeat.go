@@ -0,0 +1,163 @@
+package typed_goroutine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// What the context of a running job carries, kept in the handle of the job
+// so running it allocates nothing more
+type jobScope struct {
+	info JobInfo
+	pool checkpointer
+	// Checkpoints between yields WithPreemptiveCheckpoints, 0 if the job
+	// does not yield
+	preemptEvery uint
+	heartbeat
+}
+
+// Last heartbeat of a running job
+type heartbeat struct {
+	clock Clock
+	mu    sync.Mutex
+	at    time.Time
+	msg   string
+	done  bool
+	// Values emitted so far by a streaming job
+	parts int
+	// Whether a hedge won the last attempt of a job added with
+	// AddHedgedJob
+	hedged bool
+	// Checkpoints passed by the current run of the job
+	checkpoints uint
+	// Attached by the job with Warn
+	warnings []error
+}
+
+// Report from a running job that it is still making progress, with a
+// message describing how far it got. Does nothing when ctx does not belong
+// to a job or the job already ended. See LastHeartbeat and
+// WithStallDetector.
+func Heartbeat(ctx context.Context, msg string) {
+	if scope, ok := ctx.Value(jobInfoKey{}).(*jobScope); ok {
+		scope.beat(msg)
+	}
+}
+
+func (b *heartbeat) beat(msg string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.done {
+		b.at, b.msg = b.clock.Now(), msg
+	}
+}
+
+func (b *heartbeat) last() (time.Time, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.at, b.msg
+}
+
+// Ignore heartbeats once the job ended
+func (b *heartbeat) finish() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.done = true
+}
+
+// Time and message of the last heartbeat of the running job at index, the
+// zero time if it is not running or did not send one yet
+func (p *Pool[T]) LastHeartbeat(index int) (time.Time, string) {
+	p.beatsMu.Lock()
+	scope, ok := p.beats[index]
+	p.beatsMu.Unlock()
+	if !ok {
+		return time.Time{}, ""
+	}
+	return scope.last()
+}
+
+// Call fn for a running job that sent no heartbeat for timeout, counted
+// from its start until it sends the first one. fn receives the last
+// heartbeat, the zero time if there was none, and is called once per
+// stall: a job that keeps stalling is reported again only after another
+// heartbeat.
+func WithStallDetector[T any](timeout time.Duration, fn func(index int, name string, last time.Time, msg string)) Option[T] {
+	return func(p *Pool[T]) error {
+		if timeout <= 0 {
+			return fmt.Errorf("%w: stall timeout must be positive", ErrInvalidOption)
+		}
+		if fn == nil {
+			return fmt.Errorf("%w: nil stall hook", ErrInvalidOption)
+		}
+		p.stallTimeout, p.onStall = timeout, fn
+		return nil
+	}
+}
+
+// Make the heartbeats of a running job visible to LastHeartbeat
+func (p *Pool[T]) track(index int, scope *jobScope) {
+	p.beatsMu.Lock()
+	defer p.beatsMu.Unlock()
+	if p.beats == nil {
+		p.beats = make(map[int]*jobScope)
+	}
+	p.beats[index] = scope
+}
+
+// Key of the heartbeats of a job run by Execute
+func (p *Pool[T]) adHocBeat() int {
+	p.beatsMu.Lock()
+	defer p.beatsMu.Unlock()
+	p.adHoc++
+	return -1 - p.adHoc
+}
+
+// Ignore the heartbeats of a job once it ended
+func (p *Pool[T]) untrack(index int, scope *jobScope) {
+	scope.finish()
+	p.beatsMu.Lock()
+	defer p.beatsMu.Unlock()
+	delete(p.beats, index)
+}
+
+// Check the heartbeats of a job whenever timeout may have passed since the
+// last one, until the returned function is called
+func (p *Pool[T]) watchStall(ctx context.Context, job queuedJob[T], startedAt time.Time, beat *heartbeat) func() {
+	timer := p.clock.NewTimer(p.stallTimeout)
+	finished := make(chan struct{})
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		defer timer.Stop()
+		// Start of the stall reported last
+		var reported time.Time
+		for {
+			select {
+			case <-timer.C():
+			case <-finished:
+				return
+			}
+			at, msg := beat.last()
+			since := at
+			if since.IsZero() {
+				since = startedAt
+			}
+			if wait := p.stallTimeout - p.since(since); wait > 0 {
+				timer.Reset(wait)
+				continue
+			}
+			if since != reported {
+				reported = since
+				p.callHook(ctx, job, func() { p.onStall(job.index, job.name, at, msg) })
+			}
+			timer.Reset(p.stallTimeout)
+		}
+	}()
+	return func() {
+		close(finished)
+		<-exited
+	}
+}
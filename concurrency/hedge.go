@@ -0,0 +1,151 @@
+package typed_goroutine
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// Add a job that is executed again, up to maxHedges times, each time it did
+// not finish within delay of the execution started last. The first
+// execution to succeed wins and the others are cancelled through their
+// context, Result.Hedged tells whether a hedge won. Only if every
+// execution fails the job fails, with the error or panic of the first
+// execution that failed. Every hedge waits for a worker of its own, so
+// hedges count against MaxWorkers, while the job keeps its worker for the
+// first execution. The job finishes as soon as an execution succeeded or
+// every execution that started failed, hedges still waiting for a worker
+// then give up. Retries execute the job and its hedges again,
+// Result.Attempts does not count the hedges.
+func (p *Pool[T]) AddHedgedJob(job func(ctx context.Context) (*T, error), delay time.Duration, maxHedges int) (*JobHandle[T], error) {
+	if delay <= 0 {
+		return nil, fmt.Errorf("%w: hedge delay must be positive", ErrInvalidOption)
+	}
+	if maxHedges < 0 {
+		return nil, fmt.Errorf("%w: negative number of hedges", ErrInvalidOption)
+	}
+	return p.add(queuedJob[T]{fn: func(ctx context.Context) (*T, error) {
+		return p.hedge(ctx, job, delay, maxHedges)
+	}}, false)
+}
+
+// Outcome of one execution of a hedged job
+type hedgeOutcome[T any] struct {
+	result *T
+	err    error
+	panic  *hedgePanic
+	hedge  bool
+	// Whether the execution started, a hedge may give up waiting for a worker
+	ran bool
+	// Sent by a hedge once it got a worker, ahead of its outcome on the same
+	// channel, so it counts as executing before it can have failed
+	started bool
+}
+
+// Panic of an execution of a hedged job, raised again by the worker with
+// the stack of the goroutine that panicked
+type hedgePanic struct {
+	value any
+	stack []byte
+}
+
+// Execute a hedged job once, a panic is handed back to the worker
+func runHedge[T any](ctx context.Context, job func(ctx context.Context) (*T, error), hedge bool, outcomes chan<- hedgeOutcome[T]) {
+	out := hedgeOutcome[T]{hedge: hedge, ran: true}
+	defer func() {
+		if r := recover(); r != nil {
+			out.panic = &hedgePanic{value: r, stack: debug.Stack()}
+		}
+		outcomes <- out
+	}()
+	out.result, out.err = job(ctx)
+}
+
+// Execute a hedged job and its hedges until one of them succeeds or every
+// one that started failed. Hedges still waiting for a worker then give up,
+// and the executions still running once one succeeded are left to return
+// in the background.
+func (p *Pool[T]) hedge(ctx context.Context, job func(ctx context.Context) (*T, error), delay time.Duration, maxHedges int) (*T, error) {
+	scope, _ := ctx.Value(jobInfoKey{}).(*jobScope)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	// Room for the outcome of the job and the start and outcome of every
+	// hedge, so none of them blocks once the job returned
+	outcomes := make(chan hedgeOutcome[T], 2*maxHedges+1)
+	go runHedge(ctx, job, false, outcomes)
+	executing, waiting, hedges := 1, 0, 0
+	timer := p.clock.NewTimer(delay)
+	defer timer.Stop()
+	var failed *hedgeOutcome[T]
+	for executing+waiting > 0 {
+		if executing == 0 {
+			// Every execution failed, so do the hedges waiting for a worker
+			cancel()
+		}
+		var due <-chan time.Time
+		if executing > 0 && hedges < maxHedges {
+			due = timer.C()
+		}
+		select {
+		case out := <-outcomes:
+			if out.started {
+				waiting--
+				executing++
+				break
+			}
+			if !out.ran {
+				waiting--
+				break
+			}
+			executing--
+			if out.err == nil && out.panic == nil {
+				scope.setHedged(out.hedge)
+				return out.result, nil
+			}
+			if failed == nil {
+				failed = &out
+			}
+		case <-due:
+			hedges++
+			waiting++
+			go func() {
+				held, err := p.hold(ctx, 1)
+				if err == nil && ctx.Err() != nil {
+					p.release(held)
+					err = ctx.Err()
+				}
+				if err != nil {
+					outcomes <- hedgeOutcome[T]{err: err, hedge: true}
+					return
+				}
+				defer p.release(held)
+				outcomes <- hedgeOutcome[T]{started: true}
+				runHedge(ctx, job, true, outcomes)
+			}()
+			timer.Reset(delay)
+		}
+	}
+	scope.setHedged(false)
+	if failed.panic != nil {
+		panic(failed.panic)
+	}
+	return failed.result, failed.err
+}
+
+// Remember whether a hedge won the last attempt of the job
+func (s *jobScope) setHedged(hedged bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hedged = hedged
+}
+
+// Whether a hedge won the last attempt of the job
+func (s *jobScope) won() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hedged
+}
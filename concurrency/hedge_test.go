@@ -0,0 +1,147 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+	"github.com/demy076/typed_goroutines/concurrency/typedpooltest"
+)
+
+func TestHedgedJobFirstResponderWins(t *testing.T) {
+	clock := typedpooltest.NewClock(time.Unix(0, 0))
+	pool := typed_goroutine.NewPool[int](1, 2, typed_goroutine.WithClock[int](clock))
+	var executions atomic.Int32
+	started := make(chan struct{}, 2)
+	_, err := pool.AddHedgedJob(func(ctx context.Context) (*int, error) {
+		started <- struct{}{}
+		if executions.Add(1) == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		value := 2
+		return &value, nil
+	}, time.Second, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	results, panics := pool.Wait()
+	if len(results) != 1 || len(panics) != 0 {
+		t.Fatalf("got %d results and %d panics, want 1 result", len(results), len(panics))
+	}
+	result := results[0]
+	if !result.OK || result.Value != 2 || !result.Hedged {
+		t.Fatalf("got value %d, ok %v, hedged %v, want the hedge to win with 2", result.Value, result.OK, result.Hedged)
+	}
+	if executions.Load() != 2 {
+		t.Fatalf("job executed %d times, want 2", executions.Load())
+	}
+}
+
+func TestHedgedJobFailsWithoutWaitingForWorker(t *testing.T) {
+	clock := typedpooltest.NewClock(time.Unix(0, 0))
+	pool := typed_goroutine.NewPool[int](1, 1, typed_goroutine.WithClock[int](clock))
+	fail := make(chan struct{})
+	errFetch := errors.New("fetch failed")
+	_, err := pool.AddHedgedJob(func(ctx context.Context) (*int, error) {
+		<-fail
+		return nil, errFetch
+	}, 10*time.Millisecond, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	clock.BlockUntil(1)
+	// The hedge waits for the only worker, which the job holds
+	clock.Advance(10 * time.Millisecond)
+	close(fail)
+	done := make(chan []typed_goroutine.Result[int])
+	go func() {
+		results, _ := pool.Wait()
+		done <- results
+	}()
+	select {
+	case results := <-done:
+		if len(results) != 1 || !errors.Is(results[0].Error, errFetch) || results[0].Hedged {
+			t.Fatalf("got %+v, want the job to fail with its own error", results)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("hedged job did not finish after every execution failed")
+	}
+}
+
+func TestHedgedJobPanicKeepsStack(t *testing.T) {
+	clock := typedpooltest.NewClock(time.Unix(0, 0))
+	pool := typed_goroutine.NewPool[int](1, 1, typed_goroutine.WithClock[int](clock))
+	_, err := pool.AddHedgedJob(func(ctx context.Context) (*int, error) {
+		panic("boom")
+	}, time.Second, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, panics := pool.Wait()
+	if len(panics) != 1 || panics[0].Value != "boom" {
+		t.Fatalf("got panics %+v, want the one of the job", panics)
+	}
+	if !strings.Contains(string(panics[0].Stack), "TestHedgedJobPanicKeepsStack") {
+		t.Fatalf("stack does not show where the job panicked:\n%s", panics[0].Stack)
+	}
+}
+
+func TestHedgeFailingFastKeepsJobRunning(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		clock := typedpooltest.NewClock(time.Unix(0, 0))
+		pool := typed_goroutine.NewPool[int](1, 2, typed_goroutine.WithClock[int](clock))
+		errFetch := errors.New("fetch failed")
+		var executions atomic.Int32
+		running, cancelled := make(chan struct{}), make(chan struct{})
+		release, hedged := make(chan struct{}), make(chan struct{})
+		_, err := pool.AddHedgedJob(func(ctx context.Context) (*int, error) {
+			if executions.Add(1) > 1 {
+				defer close(hedged)
+				return nil, errFetch
+			}
+			close(running)
+			select {
+			case <-ctx.Done():
+				close(cancelled)
+				return nil, ctx.Err()
+			case <-release:
+				value := 1
+				return &value, nil
+			}
+		}, time.Second, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := pool.Run(); err != nil {
+			t.Fatal(err)
+		}
+		<-running
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		<-hedged
+		select {
+		case <-cancelled:
+			t.Fatal("job was cancelled after its hedge failed")
+		case <-time.After(20 * time.Millisecond):
+		}
+		close(release)
+		results, _ := pool.Wait()
+		if len(results) != 1 || !results[0].OK || results[0].Value != 1 || results[0].Hedged {
+			t.Fatalf("got %+v, want the job itself to succeed with 1", results)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package typed_goroutine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Error of a single item processed by Map or ForEach
+type ItemError struct {
+	// Position of the item in the input slice
+	Index int
+	Err   error
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// Create a pool with one job per item calling fn with it, sized for the
+// items. Zero workers means one per CPU. Panics if an option is invalid or
+// the queue set WithQueueSize cannot hold every item.
+func NewPoolForSlice[In, Out any](items []In, workers uint, fn func(In) (*Out, error), opts ...Option[Out]) *Pool[Out] {
+	p := NewPool[Out](uint(len(items)), workers, opts...)
+	jobs := make([]func() (*Out, error), len(items))
+	for i, item := range items {
+		jobs[i] = func() (*Out, error) {
+			return fn(item)
+		}
+	}
+	if err := p.AddJobs(jobs...); err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// Apply fn to every item using at most workers goroutines. The outputs are
+// ordered like items and hold the zero value for failed items. Every
+// failure, including panics, is returned as an *ItemError joined with
+// errors.Join.
+func Map[In, Out any](ctx context.Context, items []In, workers uint, fn func(context.Context, In) (Out, error)) ([]Out, error) {
+	if len(items) == 0 {
+		return nil, nil
+	}
+	pool, err := NewPoolWithOptions(
+		WithWorkers[Out](workers),
+		WithExpectedJobs[Out](uint(len(items))),
+		WithContext[Out](ctx),
+		WithPanicsAsErrors[Out](),
+	)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		item := items[i]
+		pool.AddJobCtx(func(ctx context.Context) (*Out, error) {
+			out, err := fn(ctx, item)
+			return &out, err
+		})
+	}
+	results, _ := pool.Wait()
+	outs := make([]Out, len(items))
+	var errs []error
+	for _, result := range results {
+		if result.Error != nil {
+			err := result.Error
+			var jobErr *JobError
+			if errors.As(err, &jobErr) {
+				err = jobErr.Err
+			}
+			errs = append(errs, &ItemError{Index: result.Index, Err: err})
+			continue
+		}
+		outs[result.Index] = *result.Result
+	}
+	return outs, errors.Join(errs...)
+}
+
+// Call fn for every item using at most workers goroutines, for work that
+// produces no value. Failures are reported like they are by Map.
+func ForEach[In any](ctx context.Context, items []In, workers uint, fn func(context.Context, In) error) error {
+	_, err := Map(ctx, items, workers, func(ctx context.Context, item In) (struct{}, error) {
+		return struct{}{}, fn(ctx, item)
+	})
+	return err
+}
+
+// Fold results into an accumulator in the order they are given, for
+// example to sum the values of the jobs that succeeded
+func Reduce[T, Acc any](results []Result[T], init Acc, fn func(Acc, Result[T]) Acc) Acc {
+	acc := init
+	for _, result := range results {
+		acc = fn(acc, result)
+	}
+	return acc
+}
+
+// Run a pool that was not started yet and fold its results as they arrive,
+// without holding all of them in memory. fn is only called from the
+// calling goroutine, and also receives skipped jobs and panics, the latter
+// as results with Panic set. Results arrive in the order the jobs finish.
+func ReduceStream[T, Acc any](p *Pool[T], init Acc, fn func(Acc, Result[T]) Acc) (Acc, error) {
+	results := p.Results()
+	if err := p.Run(); err != nil {
+		return init, err
+	}
+	acc := init
+	for result := range results {
+		acc = fn(acc, result)
+	}
+	return acc, nil
+}
+
+// Feed the results of src into a new pool running fn with at most workers
+// goroutines, starting each job of the second stage as soon as its input
+// is ready. src must not have been started, Pipe runs it with ctx and the
+// returned pool is already running, so only it needs to be waited on.
+// Failed, skipped and panicked jobs of src are forwarded as failed jobs of
+// the second stage carrying their error, so every job of src has a result
+// in the second stage. Cancelling ctx stops both stages. An error from
+// starting src other than ErrAlreadyRunning or ErrPoolFinished, such as an
+// unmet dependency, is returned together with the running pool.
+func Pipe[A, B any](ctx context.Context, src *Pool[A], workers uint, fn func(context.Context, A) (*B, error)) (*Pool[B], error) {
+	dst, err := NewPoolWithOptions(WithWorkers[B](workers), WithDynamicSubmission[B]())
+	if err != nil {
+		return nil, err
+	}
+	results := src.Results()
+	started, err := src.start(ctx)
+	if !started {
+		return nil, err
+	}
+	dst.RunWithContext(ctx)
+	go func() {
+		defer dst.Close()
+		for result := range results {
+			value, resultErr := result.Get()
+			// Keep draining src even if dst no longer accepts jobs
+			dst.add(queuedJob[B]{fn: func(ctx context.Context) (*B, error) {
+				if resultErr != nil {
+					return nil, resultErr
+				}
+				return fn(ctx, value)
+			}}, true)
+		}
+	}()
+	return dst, err
+}
+
+// Error of a chunk of items processed by MapChunked
+type ChunkError struct {
+	// Position of the first item of the chunk in the input slice
+	Start int
+	// Position after the last item of the chunk
+	End int
+	Err error
+}
+
+func (e *ChunkError) Error() string {
+	return fmt.Sprintf("items %d to %d: %v", e.Start, e.End-1, e.Err)
+}
+
+func (e *ChunkError) Unwrap() error {
+	return e.Err
+}
+
+// Apply fn to chunks of at most chunkSize items using at most workers
+// goroutines, one job per chunk, and concatenate the outputs in the order of
+// the chunks. fn may return any number of outputs for its chunk. Every
+// failed chunk, including panics, is returned as a *ChunkError joined with
+// errors.Join, its outputs are left out.
+func MapChunked[In, Out any](ctx context.Context, items []In, workers uint, chunkSize int, fn func(context.Context, []In) ([]Out, error)) ([]Out, error) {
+	if chunkSize <= 0 {
+		return nil, fmt.Errorf("%w: chunk size must be positive", ErrInvalidOption)
+	}
+	var chunks [][]In
+	for start := 0; start < len(items); start += chunkSize {
+		chunks = append(chunks, items[start:min(start+chunkSize, len(items))])
+	}
+	outs, err := Map(ctx, chunks, workers, fn)
+	var errs []error
+	for _, err := range unjoin(err) {
+		var itemErr *ItemError
+		if errors.As(err, &itemErr) {
+			start := itemErr.Index * chunkSize
+			err = &ChunkError{Start: start, End: start + len(chunks[itemErr.Index]), Err: itemErr.Err}
+		}
+		errs = append(errs, err)
+	}
+	var flat []Out
+	for _, out := range outs {
+		flat = append(flat, out...)
+	}
+	return flat, errors.Join(errs...)
+}
+
+// Errors joined by errors.Join
+func unjoin(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	if err != nil {
+		return []error{err}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package typed_goroutine_test
+
+import (
+	"testing"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+// Every job must see its own item, which a closure sharing one loop
+// variable would not before Go 1.22
+func TestNewPoolForSliceCapturesItems(t *testing.T) {
+	items := []string{"a", "bb", "ccc", "dddd"}
+	pool := typed_goroutine.NewPoolForSlice(items, 2, func(item string) (*int, error) {
+		n := len(item)
+		return &n, nil
+	})
+	results, _ := pool.Wait()
+	if len(results) != len(items) {
+		t.Fatalf("got %d results, want %d", len(results), len(items))
+	}
+	for _, result := range results {
+		if want := len(items[result.Index]); *result.Result != want {
+			t.Fatalf("job %d: got %d, want %d", result.Index, *result.Result, want)
+		}
+	}
+}
+
+func TestAddJobsKeepsOrder(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](0, 2)
+	var jobs []func() (*int, error)
+	for i := range 5 {
+		jobs = append(jobs, func() (*int, error) { return &i, nil })
+	}
+	if err := pool.AddJobs(jobs...); err != nil {
+		t.Fatal(err)
+	}
+	results, _ := pool.Wait()
+	if len(results) != 5 {
+		t.Fatalf("got %d results, want 5", len(results))
+	}
+	for _, result := range results {
+		if *result.Result != result.Index {
+			t.Fatalf("job %d returned %d", result.Index, *result.Result)
+		}
+	}
+}
+
+func TestNoJobs(t *testing.T) {
+	pool := typed_goroutine.NewPoolForSlice(nil, 2, func(int) (*int, error) { return nil, nil })
+	if results, panics := pool.Wait(); len(results) != 0 || len(panics) != 0 {
+		t.Fatalf("got %d results and %d panics without jobs", len(results), len(panics))
+	}
+	pool = typed_goroutine.NewPool[int](0, 2)
+	if err := pool.AddJobs(); err != nil {
+		t.Fatal(err)
+	}
+	if results, _ := pool.Wait(); len(results) != 0 {
+		t.Fatalf("got %d results without jobs", len(results))
+	}
+}
@@ -0,0 +1,74 @@
+package typed_goroutine
+
+import (
+	"context"
+	"sync"
+)
+
+// Result of an IOPool job together with the input it was run for
+type IOResult[In, Out any] struct {
+	Result[Out]
+	Input In
+}
+
+// Pool that runs a single function over typed inputs
+type IOPool[In, Out any] struct {
+	pool *Pool[Out]
+	fn   func(In) (*Out, error)
+	// Guards the inputs, which are indexed like the jobs of the pool
+	mu     sync.Mutex
+	inputs []In
+}
+
+// Create a pool running fn for every input added to it, zero workers means
+// one per CPU. Panics if an option is invalid.
+func NewIOPool[In, Out any](workers uint, fn func(In) (*Out, error), opts ...Option[Out]) *IOPool[In, Out] {
+	return &IOPool[In, Out]{
+		pool: NewPool[Out](0, workers, opts...),
+		fn:   fn,
+	}
+}
+
+// Add an input to the pool
+func (p *IOPool[In, Out]) Add(input In) (*JobHandle[Out], error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	handle, err := p.pool.AddJob(func() (*Out, error) {
+		return p.fn(input)
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.inputs = append(p.inputs, input)
+	return handle, nil
+}
+
+// Input added at index
+func (p *IOPool[In, Out]) Input(index int) In {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.inputs[index]
+}
+
+// Start the pool without waiting for it
+func (p *IOPool[In, Out]) Run() error {
+	return p.pool.Run()
+}
+
+// Start the pool without waiting for it, see Pool.RunWithContext
+func (p *IOPool[In, Out]) RunWithContext(ctx context.Context) error {
+	return p.pool.RunWithContext(ctx)
+}
+
+// Wait for the pool to finish, results are ordered by input index and carry
+// their input. Use Input to find the input of a panic.
+func (p *IOPool[In, Out]) Wait() (results []IOResult[In, Out], panics []PanicInfo) {
+	out, panics := p.pool.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	results = make([]IOResult[In, Out], len(out))
+	for i, result := range out {
+		results[i] = IOResult[In, Out]{Result: result, Input: p.inputs[result.Index]}
+	}
+	return results, panics
+}
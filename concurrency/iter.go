@@ -0,0 +1,52 @@
+package typed_goroutine
+
+import "iter"
+
+// Iterate over the results by job index as jobs finish, the loop ends once
+// the pool is done. Must be called before the pool starts, it subscribes to
+// Results and runs the pool once the loop begins. Panics arrive as results
+// with Panic set. Breaking out of the loop does not cancel the pool, its
+// jobs keep running and their results are discarded.
+func (p *Pool[T]) All() iter.Seq2[int, Result[T]] {
+	results := p.Results()
+	return func(yield func(int, Result[T]) bool) {
+		p.Run()
+		for result := range results {
+			if !yield(result.Index, result) {
+				// Keep draining so the workers never block on the stream
+				go func() {
+					for range results {
+					}
+				}()
+				return
+			}
+		}
+	}
+}
+
+// Iterate over the values of the jobs that succeeded by job index, see All
+func (p *Pool[T]) Successes() iter.Seq2[int, T] {
+	all := p.All()
+	return func(yield func(int, T) bool) {
+		for index, result := range all {
+			value, err := result.Get()
+			if err == nil && !yield(index, value) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over the errors of the jobs that failed, panicked or were
+// skipped by job index, see All
+func (p *Pool[T]) Failures() iter.Seq2[int, error] {
+	all := p.All()
+	return func(yield func(int, error) bool) {
+		for index, result := range all {
+			_, err := result.Get()
+			if err != nil && !yield(index, err) {
+				return
+			}
+		}
+	}
+}
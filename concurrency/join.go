@@ -0,0 +1,102 @@
+package typed_goroutine
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Option of Join2 and Join3
+type JoinOption func(*joinConfig)
+
+type joinConfig struct {
+	cancelOnFailure bool
+}
+
+// Cancel the context of every joined pool with the error of the first job
+// that fails or panics in any of them
+func JoinCancelOnFailure() JoinOption {
+	return func(c *joinConfig) {
+		c.cancelOnFailure = true
+	}
+}
+
+// Run two pools with ctx at the same time and wait for both, returning
+// their results and the errors of the jobs of both pools joined with
+// errors.Join, nil if every job succeeded. Pools that were started before
+// keep running with their own context and cannot be cancelled on failure.
+func Join2[A, B any](ctx context.Context, pa *Pool[A], pb *Pool[B], opts ...JoinOption) ([]Result[A], []Result[B], error) {
+	var ra []Result[A]
+	var rb []Result[B]
+	err := join(ctx, opts, []func(func(error)){pa.watchFailure, pb.watchFailure}, []func(context.Context) error{
+		func(ctx context.Context) error {
+			ra, _ = pa.WaitWithContext(ctx)
+			return joinErrors(ra)
+		},
+		func(ctx context.Context) error {
+			rb, _ = pb.WaitWithContext(ctx)
+			return joinErrors(rb)
+		},
+	})
+	return ra, rb, err
+}
+
+// Run three pools with ctx at the same time and wait for all of them, see
+// Join2
+func Join3[A, B, C any](ctx context.Context, pa *Pool[A], pb *Pool[B], pc *Pool[C], opts ...JoinOption) ([]Result[A], []Result[B], []Result[C], error) {
+	var ra []Result[A]
+	var rb []Result[B]
+	var rc []Result[C]
+	err := join(ctx, opts, []func(func(error)){pa.watchFailure, pb.watchFailure, pc.watchFailure}, []func(context.Context) error{
+		func(ctx context.Context) error {
+			ra, _ = pa.WaitWithContext(ctx)
+			return joinErrors(ra)
+		},
+		func(ctx context.Context) error {
+			rb, _ = pb.WaitWithContext(ctx)
+			return joinErrors(rb)
+		},
+		func(ctx context.Context) error {
+			rc, _ = pc.WaitWithContext(ctx)
+			return joinErrors(rc)
+		},
+	})
+	return ra, rb, rc, err
+}
+
+// Call every wait on its own goroutine with a context derived from ctx and
+// join their errors, watch lets each pool cancel that context on failure
+func join(ctx context.Context, opts []JoinOption, watch []func(func(error)), waits []func(context.Context) error) error {
+	var config joinConfig
+	for _, opt := range opts {
+		opt(&config)
+	}
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	if config.cancelOnFailure {
+		for _, w := range watch {
+			w(cancel)
+		}
+	}
+	errs := make([]error, len(waits))
+	var wg sync.WaitGroup
+	for i, wait := range waits {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = wait(ctx)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// Call hook with the error of every job that fails, unless the pool was
+// started already
+func (p *Pool[T]) watchFailure(hook func(err error)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.running {
+		p.onFailure = hook
+	}
+}
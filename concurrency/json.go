@@ -0,0 +1,163 @@
+package typed_goroutine
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Outcome of a run that can be persisted as JSON and read back, see
+// Pool.Summary
+type Summary[T any] struct {
+	// Name of the pool, see WithName
+	Name      string `json:"name,omitempty"`
+	Total     int    `json:"total"`
+	Completed int    `json:"completed"`
+	Failed    int    `json:"failed"`
+	Panicked  int    `json:"panicked"`
+	Skipped   int    `json:"skipped"`
+	// Time the pool ran, or has been running so far
+	Elapsed time.Duration `json:"elapsed_ns"`
+	// Results collected so far ordered by job index, with panics as
+	// results with Panic set
+	Jobs []Result[T] `json:"jobs"`
+	// Jobs of Jobs that did not succeed, the warnings of Jobs, and the
+	// jobs that ran longest
+	Failures []JobFailure `json:"failures,omitempty"`
+	Warnings []JobWarning `json:"warnings,omitempty"`
+	Slowest  []JobTiming  `json:"slowest,omitempty"`
+}
+
+// Summarize the run of the pool, best called once it was waited on.
+// Results delivered through Results are not included.
+func (p *Pool[T]) Summary() Summary[T] {
+	stats := p.Stats()
+	summary := Summary[T]{
+		Name:      p.name,
+		Total:     stats.Total,
+		Completed: stats.Completed,
+		Failed:    stats.Failed,
+		Panicked:  stats.Panicked,
+		Skipped:   stats.Skipped,
+		Elapsed:   stats.Elapsed,
+		Jobs:      p.collected(),
+	}
+	summary.describe()
+	return summary
+}
+
+// Form of a Result in JSON
+type resultJSON[T any] struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	// Only set for iterations of a repeated job
+	Iteration int  `json:"iteration,omitempty"`
+	Part      int  `json:"part,omitempty"`
+	OK        bool `json:"ok"`
+	Skipped   bool `json:"skipped,omitempty"`
+	// Only set if the job succeeded
+	Value     *T         `json:"value,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Warnings  []string   `json:"warnings,omitempty"`
+	Panic     *panicJSON `json:"panic,omitempty"`
+	Policy    string     `json:"policy,omitempty"`
+	Worker    int        `json:"worker"`
+	Attempts  int        `json:"attempts"`
+	StartedAt time.Time  `json:"started_at"`
+	// Durations in nanoseconds
+	Duration  time.Duration `json:"duration_ns"`
+	QueueWait time.Duration `json:"queue_wait_ns"`
+	Slow      bool          `json:"slow,omitempty"`
+	Hedged    bool          `json:"hedged,omitempty"`
+}
+
+// Form of a PanicInfo in JSON, the panic value is formatted with %v
+type panicJSON struct {
+	Value string `json:"value"`
+	Stack string `json:"stack,omitempty"`
+}
+
+// Encode the result with its error and panic value as strings, the value
+// is only included if the job succeeded
+func (r Result[T]) MarshalJSON() ([]byte, error) {
+	out := resultJSON[T]{
+		Index:     r.Index,
+		Name:      r.Name,
+		Iteration: r.Iteration,
+		Part:      r.Part,
+		OK:        r.OK,
+		Skipped:   r.Skipped,
+		Policy:    r.Policy,
+		Worker:    r.Worker,
+		Attempts:  r.Attempts,
+		StartedAt: r.StartedAt,
+		Duration:  r.Duration,
+		QueueWait: r.QueueWait,
+		Slow:      r.Slow,
+		Hedged:    r.Hedged,
+	}
+	if r.OK {
+		out.Value = &r.Value
+	}
+	if r.Error != nil {
+		out.Error = r.Error.Error()
+	}
+	for _, warning := range r.Warnings {
+		out.Warnings = append(out.Warnings, warning.Error())
+	}
+	if r.Panic != nil {
+		out.Panic = &panicJSON{Value: fmt.Sprint(r.Panic.Value), Stack: string(r.Panic.Stack)}
+	}
+	return json.Marshal(out)
+}
+
+// Decode a result encoded by MarshalJSON. The error and the panic value
+// come back as opaque values that only keep their message, so errors.Is
+// no longer matches their cause.
+func (r *Result[T]) UnmarshalJSON(data []byte) error {
+	var in resultJSON[T]
+	if err := json.Unmarshal(data, &in); err != nil {
+		return err
+	}
+	*r = Result[T]{
+		OK:        in.OK,
+		Skipped:   in.Skipped,
+		Index:     in.Index,
+		Name:      in.Name,
+		Iteration: in.Iteration,
+		Part:      in.Part,
+		Policy:    in.Policy,
+		Meta: Meta{
+			Worker:    in.Worker,
+			Attempts:  in.Attempts,
+			StartedAt: in.StartedAt,
+			Duration:  in.Duration,
+			QueueWait: in.QueueWait,
+			Slow:      in.Slow,
+			Hedged:    in.Hedged,
+		},
+	}
+	if in.Value != nil {
+		r.Value = *in.Value
+		r.Result = &r.Value
+	}
+	if in.Error != "" {
+		r.Error = errors.New(in.Error)
+	}
+	for _, warning := range in.Warnings {
+		r.Warnings = append(r.Warnings, errors.New(warning))
+	}
+	if in.Panic != nil {
+		r.Panic = &PanicInfo{
+			Value:     in.Panic.Value,
+			Index:     in.Index,
+			Name:      in.Name,
+			Stack:     []byte(in.Panic.Stack),
+			StartedAt: in.StartedAt,
+			Duration:  in.Duration,
+			Attempts:  in.Attempts,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package typed_goroutine_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+	"github.com/demy076/typed_goroutines/concurrency/typedpooltest"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// Compare got with the golden file name in testdata, or rewrite it with -update
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// Summary of a pool named audit that ran a job succeeding with warnings, a
+// failing one and a panicking one on a clock that does not move
+func mixedSummary(t *testing.T) typed_goroutine.Summary[int] {
+	t.Helper()
+	pool := typed_goroutine.NewPool[int](3, 1,
+		typed_goroutine.WithName[int]("audit"),
+		typed_goroutine.WithClock[int](typedpooltest.NewClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))),
+	)
+	if _, err := pool.AddJobCtx(func(ctx context.Context) (*int, error) {
+		typed_goroutine.Warn(ctx, errors.New("skipped row 7"))
+		value := 42
+		return &value, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.AddNamedJob("fetch", func() (*int, error) { return nil, errors.New("connection refused") }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.AddJob(func() (*int, error) { panic("oops") }); err != nil {
+		t.Fatal(err)
+	}
+	pool.Wait()
+	summary := pool.Summary()
+	// Stacks differ between machines
+	for i, job := range summary.Jobs {
+		if job.Panic != nil {
+			info := *job.Panic
+			info.Stack = nil
+			summary.Jobs[i].Panic = &info
+		}
+	}
+	return summary
+}
+
+func TestSummaryJSON(t *testing.T) {
+	got, err := json.MarshalIndent(mixedSummary(t), "", "\t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden(t, "summary.json", append(got, '\n'))
+
+	var decoded typed_goroutine.Summary[int]
+	if err := json.Unmarshal(got, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	again, err := json.MarshalIndent(decoded, "", "\t")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, got) {
+		t.Fatalf("round trip changed the summary:\n%s", again)
+	}
+}
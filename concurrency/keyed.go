@@ -0,0 +1,72 @@
+package typed_goroutine
+
+// Add a job that is collapsed with the other jobs of the same key that have
+// not finished yet: only the first one runs and every job of the key gets a
+// copy of its result, panics included, at its own index. Once it finished,
+// a job added with the same key runs again. An empty key adds the job like
+// AddJob. Cancelling the job that runs cancels every job of its key.
+func (p *Pool[T]) AddJobKeyed(key string, job func() (*T, error)) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{plain: job, key: key}, false)
+}
+
+// Submit a job collapsed by key, see AddJobKeyed and Submit
+func (p *Pool[T]) SubmitKeyed(key string, job func() (*T, error)) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{plain: job, key: key}, true)
+}
+
+// Register a keyed job as a follower of the job of its key that has not
+// finished yet, reports false if it is the first one and has to be queued.
+// The caller must hold the lock of the pool.
+func (p *Pool[T]) follow(job queuedJob[T]) bool {
+	if p.keyed == nil {
+		p.keyed = make(map[string][]queuedJob[T])
+	}
+	key := job.key
+	if _, ok := p.keyed[key]; !ok {
+		p.keyed[key] = nil
+		return false
+	}
+	// A follower never runs, so it must not release the key
+	job.key = ""
+	p.keyed[key] = append(p.keyed[key], job)
+	return true
+}
+
+// Release the key of a job that finished, returning the jobs that follow it
+func (p *Pool[T]) followers(job queuedJob[T]) []queuedJob[T] {
+	if job.key == "" {
+		return nil
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	followers := p.keyed[job.key]
+	delete(p.keyed, job.key)
+	return followers
+}
+
+// Report the result of a keyed job for the jobs that follow it
+func (p *Pool[T]) fanOut(job queuedJob[T], result Result[T]) {
+	for _, follower := range p.followers(job) {
+		if !follower.handle.start() {
+			p.skip(follower, ErrJobCancelled)
+			continue
+		}
+		p.emit(follower, result.forJob(follower))
+	}
+}
+
+// Copy of a result labelled with another job
+func (r Result[T]) forJob(job queuedJob[T]) Result[T] {
+	r.Index, r.Name, r.Policy = job.index, job.name, job.policyName()
+	if jobErr, ok := r.Error.(*JobError); ok {
+		copied := *jobErr
+		copied.Index, copied.Name = job.index, job.name
+		r.Error = &copied
+	}
+	if r.Panic != nil {
+		copied := *r.Panic
+		copied.Index, copied.Name = job.index, job.name
+		r.Panic = &copied
+	}
+	return r
+}
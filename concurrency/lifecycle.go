@@ -0,0 +1,80 @@
+package typed_goroutine
+
+import "fmt"
+
+// Stage in the life of a pool
+type PoolState int
+
+const (
+	// Not started yet, jobs can be added
+	PoolIdle PoolState = iota
+	// Started and running jobs
+	PoolRunning
+	// Stopped, waiting for the running jobs to finish
+	PoolStopping
+	// Every job finished, only Reset returns the pool to PoolIdle
+	PoolDone
+)
+
+func (s PoolState) String() string {
+	switch s {
+	case PoolIdle:
+		return "idle"
+	case PoolRunning:
+		return "running"
+	case PoolStopping:
+		return "stopping"
+	case PoolDone:
+		return "done"
+	}
+	return fmt.Sprintf("PoolState(%d)", int(s))
+}
+
+// Current stage of the pool
+func (p *Pool[T]) State() PoolState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.state()
+}
+
+// Current stage of the pool, must be called with the lock held
+func (p *Pool[T]) state() PoolState {
+	if !p.running {
+		return PoolIdle
+	}
+	select {
+	case <-p.done:
+		return PoolDone
+	default:
+	}
+	if p.stopped.Load() {
+		return PoolStopping
+	}
+	return PoolRunning
+}
+
+// Error for starting a pool that already started, must be called with the
+// lock held
+func (p *Pool[T]) startedErr() error {
+	if p.state() == PoolDone {
+		return ErrPoolFinished
+	}
+	return ErrAlreadyRunning
+}
+
+// Error for pausing a pool that is not running, must be called with the
+// lock held
+func (p *Pool[T]) notActiveErr() error {
+	if p.state() == PoolDone {
+		return ErrPoolFinished
+	}
+	return ErrNotRunning
+}
+
+// Channel closed once the pool is done, read under the lock since Reset
+// replaces it
+func (p *Pool[T]) doneChan() <-chan struct{} {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.done
+}
@@ -0,0 +1,170 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+// Pool of n jobs returning their index, counting how often they executed
+func countingPool(t *testing.T, n int, executions *atomic.Int32) *typed_goroutine.Pool[int] {
+	t.Helper()
+	pool := typed_goroutine.NewPool[int](uint(n), 2)
+	for i := range n {
+		if _, err := pool.AddJob(func() (*int, error) {
+			executions.Add(1)
+			return &i, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return pool
+}
+
+func TestRunThenWaitSchedulesOnce(t *testing.T) {
+	var executions atomic.Int32
+	pool := countingPool(t, 5, &executions)
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Run(); !errors.Is(err, typed_goroutine.ErrAlreadyRunning) && !errors.Is(err, typed_goroutine.ErrPoolFinished) {
+		t.Fatalf("second Run returned %v, want ErrAlreadyRunning or ErrPoolFinished", err)
+	}
+	results, _ := pool.Wait()
+	if len(results) != 5 || executions.Load() != 5 {
+		t.Fatalf("got %d results of %d executions, want 5 of each", len(results), executions.Load())
+	}
+	if err := pool.Run(); !errors.Is(err, typed_goroutine.ErrPoolFinished) {
+		t.Fatalf("Run after Wait returned %v, want ErrPoolFinished", err)
+	}
+}
+
+func TestWaitRunsImplicitlyAndRepeats(t *testing.T) {
+	var executions atomic.Int32
+	pool := countingPool(t, 3, &executions)
+	first, _ := pool.Wait()
+	second, _ := pool.Wait()
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("second Wait returned %+v, want %+v", second, first)
+	}
+	if executions.Load() != 3 {
+		t.Fatalf("jobs executed %d times, want 3", executions.Load())
+	}
+}
+
+// Pool of one job brought into state, its job blocks while the pool is
+// running or stopping until the test ends
+func poolIn(t *testing.T, state typed_goroutine.PoolState) *typed_goroutine.Pool[int] {
+	t.Helper()
+	pool := typed_goroutine.NewPool[int](1, 1)
+	started, release := make(chan struct{}), make(chan struct{})
+	if _, err := pool.AddJob(func() (*int, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if state == typed_goroutine.PoolIdle {
+		return pool
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	switch state {
+	case typed_goroutine.PoolStopping:
+		// Returns right away, the job keeps running
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		pool.Stop(ctx)
+	case typed_goroutine.PoolDone:
+		close(release)
+		pool.Wait()
+	}
+	if state != typed_goroutine.PoolDone {
+		t.Cleanup(func() {
+			close(release)
+			pool.Wait()
+		})
+	}
+	if got := pool.State(); got != state {
+		t.Fatalf("pool is %v, want %v", got, state)
+	}
+	return pool
+}
+
+func TestMethodsInEveryState(t *testing.T) {
+	methods := []struct {
+		name string
+		call func(*typed_goroutine.Pool[int]) error
+	}{
+		{"Run", (*typed_goroutine.Pool[int]).Run},
+		{"AddJob", func(p *typed_goroutine.Pool[int]) error {
+			_, err := p.AddJob(noop)
+			return err
+		}},
+		{"AddJobs", func(p *typed_goroutine.Pool[int]) error { return p.AddJobs(noop) }},
+		{"Pause", (*typed_goroutine.Pool[int]).Pause},
+		{"Resume", (*typed_goroutine.Pool[int]).Resume},
+		{"Reset", (*typed_goroutine.Pool[int]).Reset},
+	}
+	// Error of every method in every state, in the order of methods
+	for state, want := range map[typed_goroutine.PoolState][]error{
+		typed_goroutine.PoolIdle: {nil, nil, nil,
+			typed_goroutine.ErrNotRunning, typed_goroutine.ErrNotPaused, nil},
+		typed_goroutine.PoolRunning: {typed_goroutine.ErrAlreadyRunning, typed_goroutine.ErrAlreadyRunning, typed_goroutine.ErrAlreadyRunning,
+			nil, typed_goroutine.ErrNotPaused, typed_goroutine.ErrAlreadyRunning},
+		typed_goroutine.PoolStopping: {typed_goroutine.ErrAlreadyRunning, typed_goroutine.ErrAlreadyRunning, typed_goroutine.ErrAlreadyRunning,
+			nil, typed_goroutine.ErrNotPaused, typed_goroutine.ErrAlreadyRunning},
+		typed_goroutine.PoolDone: {typed_goroutine.ErrPoolFinished, typed_goroutine.ErrPoolFinished, typed_goroutine.ErrPoolFinished,
+			typed_goroutine.ErrPoolFinished, typed_goroutine.ErrPoolFinished, nil},
+	} {
+		for i, method := range methods {
+			t.Run(state.String()+"/"+method.name, func(t *testing.T) {
+				if err := method.call(poolIn(t, state)); !errors.Is(err, want[i]) {
+					t.Fatalf("got %v, want %v", err, want[i])
+				}
+			})
+		}
+	}
+}
+
+func TestConcurrentWaiters(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](20, 4)
+	for i := range 20 {
+		if _, err := pool.AddJob(func() (*int, error) {
+			if i%5 == 0 {
+				panic(i)
+			}
+			return &i, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	const waiters = 10
+	results := make([][]typed_goroutine.Result[int], waiters)
+	panics := make([][]typed_goroutine.PanicInfo, waiters)
+	var wg sync.WaitGroup
+	for i := range waiters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], panics[i] = pool.Wait()
+		}()
+	}
+	wg.Wait()
+	if len(results[0]) != 20 || len(panics[0]) != 4 {
+		t.Fatalf("got %d results and %d panics, want 20 and 4", len(results[0]), len(panics[0]))
+	}
+	for i := 1; i < waiters; i++ {
+		if !reflect.DeepEqual(results[i], results[0]) || !reflect.DeepEqual(panics[i], panics[0]) {
+			t.Fatalf("waiter %d saw other results than waiter 0", i)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package typed_goroutine
+
+import (
+	"context"
+	"sync"
+)
+
+// Counting limit on the weight of the running jobs that can be resized
+// while jobs hold part of it
+type workerLimit struct {
+	mu   sync.Mutex
+	size int64
+	used int64
+	// Closed and replaced whenever weight is released or the limit grows,
+	// nil while nobody waits
+	changed chan struct{}
+}
+
+func newWorkerLimit(size uint) *workerLimit {
+	return &workerLimit{size: int64(size)}
+}
+
+// Wait until weight fits within the limit or ctx is done. A weight larger
+// than the limit, which can happen after shrinking it, takes the whole
+// limit, so the weight actually held is returned.
+func (l *workerLimit) acquire(ctx context.Context, weight int64) (int64, error) {
+	for {
+		l.mu.Lock()
+		held := min(weight, l.size)
+		if l.used+held <= l.size {
+			l.used += held
+			l.mu.Unlock()
+			return held, nil
+		}
+		if l.changed == nil {
+			l.changed = make(chan struct{})
+		}
+		changed := l.changed
+		l.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// Give back weight returned by acquire
+func (l *workerLimit) release(weight int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.used -= weight
+	l.notify()
+}
+
+// Change the limit, jobs holding more than a smaller limit keep running
+// and no further job starts until they are below it
+func (l *workerLimit) resize(size uint) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.size = int64(size)
+	l.notify()
+}
+
+// Current limit
+func (l *workerLimit) limit() uint {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return uint(l.size)
+}
+
+// Wake up a waiting acquire, the caller must hold the lock
+func (l *workerLimit) notify() {
+	if l.changed != nil {
+		close(l.changed)
+		l.changed = nil
+	}
+}
@@ -0,0 +1,188 @@
+package typed_goroutine
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Budget of concurrent work that a pool shares with other code, see
+// WithLimiter. A job takes as much of it as it weighs.
+type Limiter interface {
+	// Wait until n units are free and take them, or fail once ctx is done
+	Acquire(ctx context.Context, n int64) error
+	// Give back n units taken by Acquire
+	Release(n int64)
+}
+
+// Take every job's weight from limiter before the job starts and give it
+// back once the job ended, also if it panicked or was cancelled. The pool
+// still never runs more jobs at once than it has workers. A job whose
+// Acquire fails is skipped with ErrAcquiringSemaphore wrapping the error.
+// A pool takes from one limiter only, so this cannot be combined with
+// WithSharedLimiter or WithParentLimit.
+func WithLimiter[T any](limiter Limiter) Option[T] {
+	return func(p *Pool[T]) error {
+		if limiter == nil {
+			return fmt.Errorf("%w: nil limiter", ErrInvalidOption)
+		}
+		return p.limitBy(limiter)
+	}
+}
+
+// Set the limiter jobs take their weight from, unless another one was set
+// by an earlier option
+func (p *Pool[T]) limitBy(limiter Limiter) error {
+	if p.sharedLimit != nil && p.sharedLimit != limiter {
+		return fmt.Errorf("%w: only one of WithLimiter, WithSharedLimiter and WithParentLimit can be used", ErrInvalidOption)
+	}
+	p.sharedLimit = limiter
+	return nil
+}
+
+// Limiter handing out n tokens through a buffered channel
+type TokenLimiter struct {
+	tokens chan struct{}
+	// Held while taking tokens, so two jobs taking several tokens each
+	// cannot end up holding part of what they need
+	acquiring chan struct{}
+}
+
+// Create a limiter with n tokens
+func NewTokenLimiter(n int) *TokenLimiter {
+	l := &TokenLimiter{tokens: make(chan struct{}, n), acquiring: make(chan struct{}, 1)}
+	for i := 0; i < n; i++ {
+		l.tokens <- struct{}{}
+	}
+	return l
+}
+
+// Take n tokens, waiting until they are free or ctx is done. Taking more
+// tokens than the limiter has fails right away.
+func (l *TokenLimiter) Acquire(ctx context.Context, n int64) error {
+	if n > int64(cap(l.tokens)) {
+		return fmt.Errorf("acquiring %d of %d tokens", n, cap(l.tokens))
+	}
+	select {
+	case l.acquiring <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-l.acquiring }()
+	for taken := int64(0); taken < n; taken++ {
+		select {
+		case <-l.tokens:
+		case <-ctx.Done():
+			l.Release(taken)
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Give back n tokens
+func (l *TokenLimiter) Release(n int64) {
+	for i := int64(0); i < n; i++ {
+		l.tokens <- struct{}{}
+	}
+}
+
+// Limiter capping the combined weight of the jobs of every pool created
+// with it. Waiting jobs are served first come first served, and since a
+// pool waits for one job at a time, pools take turns: a pool with many
+// jobs cannot starve one with a few.
+type SharedLimiter struct {
+	mu      sync.Mutex
+	size    int64
+	used    int64
+	waiters list.List
+}
+
+// Job of a pool waiting in a SharedLimiter, ready is closed once its
+// weight was taken for it
+type sharedWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// Create a limiter allowing jobs of a total weight of size to run at once
+func NewSharedLimiter(size int64) *SharedLimiter {
+	return &SharedLimiter{size: size}
+}
+
+// Take part of the limit for every job like WithLimiter, on top of the
+// workers of the pool
+func WithSharedLimiter[T any](limiter *SharedLimiter) Option[T] {
+	return func(p *Pool[T]) error {
+		if limiter == nil {
+			return fmt.Errorf("%w: nil shared limiter", ErrInvalidOption)
+		}
+		return p.limitBy(limiter)
+	}
+}
+
+// Take n once it is free and every job that waited before was served, or
+// fail once ctx is done. Taking more than the limit fails right away.
+func (l *SharedLimiter) Acquire(ctx context.Context, n int64) error {
+	l.mu.Lock()
+	if n > l.size {
+		l.mu.Unlock()
+		return fmt.Errorf("acquiring %d of %d", n, l.size)
+	}
+	if l.waiters.Len() == 0 && l.used+n <= l.size {
+		l.used += n
+		l.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	elem := l.waiters.PushBack(&sharedWaiter{n: n, ready: ready})
+	l.mu.Unlock()
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-ready:
+		// Served in the meantime, so give it back
+		l.used -= n
+		l.serve()
+	default:
+		l.waiters.Remove(elem)
+		// The waiters behind it may fit now
+		l.serve()
+	}
+	return ctx.Err()
+}
+
+// Give back n taken by Acquire
+func (l *SharedLimiter) Release(n int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.used -= n
+	l.serve()
+}
+
+// Weight of the jobs currently holding part of the limit
+func (l *SharedLimiter) InUse() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.used
+}
+
+// Hand out the limit to the waiters in order until the next one does not
+// fit, the caller must hold the lock
+func (l *SharedLimiter) serve() {
+	for front := l.waiters.Front(); front != nil; front = l.waiters.Front() {
+		waiter := front.Value.(*sharedWaiter)
+		if l.used+waiter.n > l.size {
+			return
+		}
+		l.used += waiter.n
+		l.waiters.Remove(front)
+		close(waiter.ready)
+	}
+}
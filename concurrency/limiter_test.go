@@ -0,0 +1,67 @@
+package typed_goroutine_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+func TestSharedLimiterCapsPools(t *testing.T) {
+	limiter := typed_goroutine.NewSharedLimiter(4)
+	var inFlight, most atomic.Int32
+	job := func() (*int, error) {
+		now := inFlight.Add(1)
+		for {
+			seen := most.Load()
+			if now <= seen || most.CompareAndSwap(seen, now) {
+				break
+			}
+		}
+		if used := limiter.InUse(); used > 4 {
+			t.Errorf("limiter has %d in use, want at most 4", used)
+		}
+		time.Sleep(time.Millisecond)
+		inFlight.Add(-1)
+		return nil, nil
+	}
+	var wg sync.WaitGroup
+	for _, jobs := range []int{200, 3} {
+		pool := typed_goroutine.NewPool[int](uint(jobs), 8, typed_goroutine.WithSharedLimiter[int](limiter))
+		for range jobs {
+			if _, err := pool.AddJob(job); err != nil {
+				t.Fatal(err)
+			}
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results, _ := pool.Wait()
+			for _, result := range results {
+				if !result.OK {
+					t.Errorf("job %d failed: %v", result.Index, result.Error)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if most.Load() > 4 {
+		t.Fatalf("%d jobs ran at once, want at most 4", most.Load())
+	}
+	if used := limiter.InUse(); used != 0 {
+		t.Fatalf("limiter has %d in use after the pools finished", used)
+	}
+}
+
+func TestOnlyOneLimiter(t *testing.T) {
+	_, err := typed_goroutine.NewPoolWithOptions(
+		typed_goroutine.WithLimiter[int](typed_goroutine.NewTokenLimiter(2)),
+		typed_goroutine.WithSharedLimiter[int](typed_goroutine.NewSharedLimiter(2)),
+	)
+	if !errors.Is(err, typed_goroutine.ErrInvalidOption) {
+		t.Fatalf("got %v, want ErrInvalidOption for two limiters", err)
+	}
+}
@@ -0,0 +1,80 @@
+package typed_goroutine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+// Log the lifecycle of the pool to logger: when it starts and finishes at
+// info level, and every job at the level of its outcome, debug when it
+// starts or succeeds, warn when it fails and error when it panics. The
+// logger is never called while the pool holds its lock.
+func WithLogger[T any](logger *slog.Logger) Option[T] {
+	return func(p *Pool[T]) error {
+		if logger == nil {
+			return fmt.Errorf("%w: nil logger", ErrInvalidOption)
+		}
+		p.logger = logger
+		return nil
+	}
+}
+
+// Only log jobs at level or above, for example slog.LevelWarn to log
+// failures and panics but not every job, while the start and end of the
+// pool are still logged
+func WithJobLogLevel[T any](level slog.Level) Option[T] {
+	return func(p *Pool[T]) error {
+		p.jobLogLevel = level
+		return nil
+	}
+}
+
+// Log an event of the pool itself
+func (p *Pool[T]) logPool(ctx context.Context, msg string, attrs ...slog.Attr) {
+	if p.name != "" {
+		attrs = append(attrs, slog.String("pool", p.name))
+	}
+	p.logger.LogAttrs(ctx, slog.LevelInfo, msg, attrs...)
+}
+
+// Log an event of a job, unless it is below the job log level
+func (p *Pool[T]) logJob(ctx context.Context, level slog.Level, msg string, index int, name string, attrs ...slog.Attr) {
+	if level < p.jobLogLevel || !p.logger.Enabled(ctx, level) {
+		return
+	}
+	attrs = append(attrs, slog.Int("job", index), slog.String("name", name))
+	if p.name != "" {
+		attrs = append(attrs, slog.String("pool", p.name))
+	}
+	p.logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// Log how a job ended
+func (p *Pool[T]) logResult(ctx context.Context, result Result[T]) {
+	duration := slog.Duration("duration", result.Duration)
+	switch {
+	case result.Panic != nil:
+		p.logJob(ctx, slog.LevelError, "job panicked", result.Index, result.Name, duration, slog.Any("panic", result.Panic.Value))
+	case result.Error != nil:
+		if isPanicError(result.Error) {
+			p.logJob(ctx, slog.LevelError, "job panicked", result.Index, result.Name, duration, slog.Any("error", result.Error))
+		} else {
+			p.logJob(ctx, slog.LevelWarn, "job failed", result.Index, result.Name, duration, slog.Any("error", result.Error), slog.Int("attempts", result.Attempts))
+		}
+	default:
+		p.logJob(ctx, slog.LevelDebug, "job succeeded", result.Index, result.Name, duration)
+	}
+}
+
+// Log that the pool finished along with its counters
+func (p *Pool[T]) logFinished(ctx context.Context) {
+	stats := p.Stats()
+	p.logPool(ctx, "pool finished",
+		slog.Duration("duration", stats.Elapsed),
+		slog.Int("completed", stats.Completed),
+		slog.Int("failed", stats.Failed),
+		slog.Int("panicked", stats.Panicked),
+		slog.Int("skipped", stats.Skipped),
+	)
+}
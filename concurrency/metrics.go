@@ -0,0 +1,50 @@
+package typed_goroutine
+
+import (
+	"fmt"
+	"time"
+)
+
+// Receives metrics of the jobs of pools, called from the worker goroutines
+// of every pool it is configured for and so must be safe for concurrent
+// use. Every started job is followed by exactly one of the other calls, the
+// jobs in flight are those started but not ended yet. Skipped jobs are
+// never started and not reported.
+type Collector interface {
+	// A job of the named pool started
+	JobStarted(pool string)
+	// A job returned without an error after running for duration
+	JobSucceeded(pool string, duration time.Duration)
+	// A job returned an error after running for duration
+	JobFailed(pool string, duration time.Duration)
+	// A job panicked after running for duration
+	JobPanicked(pool string, duration time.Duration)
+}
+
+// Report the jobs of the pool to collector, labelled with the name of the
+// pool
+func WithCollector[T any](collector Collector) Option[T] {
+	return func(p *Pool[T]) error {
+		if collector == nil {
+			return fmt.Errorf("%w: nil collector", ErrInvalidOption)
+		}
+		p.collector = collector
+		return nil
+	}
+}
+
+// Report the end of a job to the collector
+func (p *Pool[T]) collectEnd(result Result[T]) {
+	switch {
+	case result.Panic != nil:
+		p.collector.JobPanicked(p.name, result.Duration)
+	case result.Error == nil:
+		p.collector.JobSucceeded(p.name, result.Duration)
+	default:
+		if isPanicError(result.Error) {
+			p.collector.JobPanicked(p.name, result.Duration)
+		} else {
+			p.collector.JobFailed(p.name, result.Duration)
+		}
+	}
+}
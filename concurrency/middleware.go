@@ -0,0 +1,88 @@
+package typed_goroutine
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Function run for a job, as seen by middleware
+type JobFunc[T any] func(ctx context.Context) (*T, error)
+
+// Wraps the function of every job to change how it runs, see WithMiddleware
+type Middleware[T any] func(next JobFunc[T]) JobFunc[T]
+
+// Wrap every job in mw, the first middleware being the outermost. The
+// middleware wraps each attempt, so a retried job passes through it once
+// per attempt, and a panic it does not recover is handled like a panic of
+// the job. JobInfoFromContext tells the middleware which job it runs.
+// Jobs returning a value are passed through it with a pointer to their
+// value.
+func WithMiddleware[T any](mw ...Middleware[T]) Option[T] {
+	return func(p *Pool[T]) error {
+		for _, m := range mw {
+			if m == nil {
+				return fmt.Errorf("%w: nil middleware", ErrInvalidOption)
+			}
+		}
+		p.middleware = append(p.middleware, mw...)
+		return nil
+	}
+}
+
+// Middleware cancelling the context of each attempt of a job after d. Unlike
+// WithJobTimeout the time is measured per attempt and the job's error is
+// reported as returned. The time is measured by the clock of the pool.
+func TimeoutMiddleware[T any](d time.Duration) Middleware[T] {
+	return func(next JobFunc[T]) JobFunc[T] {
+		return func(ctx context.Context) (*T, error) {
+			var clock Clock = realClock{}
+			if scope, ok := ctx.Value(jobInfoKey{}).(*jobScope); ok && scope.clock != nil {
+				clock = scope.clock
+			}
+			ctx, cancel := withTimeout(ctx, clock, d)
+			defer cancel()
+			return next(ctx)
+		}
+	}
+}
+
+// Identity of the job a context belongs to
+type JobInfo struct {
+	// Position of the job in the order it was added to the pool
+	Index int
+	// Name of the job, its index unless it was added with a name
+	Name string
+	// ID of the worker running the job
+	Worker int
+}
+
+// Key of the JobInfo in the context of a job
+type jobInfoKey struct{}
+
+// Which job ctx, as passed to a job or middleware, belongs to
+func JobInfoFromContext(ctx context.Context) (JobInfo, bool) {
+	scope, ok := ctx.Value(jobInfoKey{}).(*jobScope)
+	if !ok {
+		return JobInfo{}, false
+	}
+	return scope.info, true
+}
+
+// Function of a job wrapped in the middleware of the pool
+func (p *Pool[T]) wrap(job queuedJob[T]) JobFunc[T] {
+	fn := JobFunc[T](job.fn)
+	if job.plain != nil {
+		fn = ignoreContext(job.plain)
+	}
+	if job.value != nil {
+		fn = func(ctx context.Context) (*T, error) {
+			value, err := job.value()
+			return &value, err
+		}
+	}
+	for i := len(p.middleware) - 1; i >= 0; i-- {
+		fn = p.middleware[i](fn)
+	}
+	return fn
+}
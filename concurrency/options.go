@@ -0,0 +1,424 @@
+package typed_goroutine
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Configure optional behaviour of a pool, an option returns an error when
+// it was given invalid arguments
+type Option[T any] func(*Pool[T]) error
+
+// Name of the pool, used to tell the metrics of pools apart
+func WithName[T any](name string) Option[T] {
+	return func(p *Pool[T]) error {
+		p.name = name
+		return nil
+	}
+}
+
+// Maximum number of jobs running at the same time, zero means one per CPU
+func WithWorkers[T any](n uint) Option[T] {
+	return func(p *Pool[T]) error {
+		p.maxWorkers = n
+		return nil
+	}
+}
+
+// Number of jobs the pool is expected to run, used to size its storage. Only
+// a hint, more jobs can be added and the storage for results is only
+// allocated if they are kept for Wait.
+func WithExpectedJobs[T any](n uint) Option[T] {
+	return func(p *Pool[T]) error {
+		p.expectedJobs = n
+		return nil
+	}
+}
+
+// Context used by Run and Wait, defaults to context.Background
+func WithContext[T any](ctx context.Context) Option[T] {
+	return func(p *Pool[T]) error {
+		if ctx == nil {
+			return fmt.Errorf("%w: nil context", ErrInvalidOption)
+		}
+		p.ctx = ctx
+		return nil
+	}
+}
+
+// Derive the context of every run from ctx, so jobs see its values and
+// are cancelled with its cause once it is done, also when the pool runs
+// with another context, whose values are then not visible to jobs. The
+// cause of the context of a job tells why it was cancelled: the cause of
+// either context, ErrPoolDeadlineExceeded, or ErrPoolStopped or
+// ErrPoolFinished for a job still running once the pool is done.
+func WithBaseContext[T any](ctx context.Context) Option[T] {
+	return func(p *Pool[T]) error {
+		if ctx == nil {
+			return fmt.Errorf("%w: nil base context", ErrInvalidOption)
+		}
+		p.baseCtx = ctx
+		return nil
+	}
+}
+
+// Stop starting jobs at deadline: jobs that have not started by then are
+// skipped with ErrPoolDeadlineExceeded and the context of the running jobs
+// is cancelled with it as its cause. Jobs that ignore their context still
+// hold up Wait until they return.
+func WithDeadline[T any](deadline time.Time) Option[T] {
+	return func(p *Pool[T]) error {
+		if deadline.IsZero() {
+			return fmt.Errorf("%w: zero deadline", ErrInvalidOption)
+		}
+		p.deadline = deadline
+		return nil
+	}
+}
+
+// Give every run of the pool d from when it starts, see WithDeadline. The
+// earlier deadline applies if both are set.
+func WithTimeout[T any](d time.Duration) Option[T] {
+	return func(p *Pool[T]) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: pool timeout must be positive", ErrInvalidOption)
+		}
+		p.timeout = d
+		return nil
+	}
+}
+
+// Execute a job that returned an error up to max more times, waiting for
+// backoff(attempt) in between while holding on to its worker. A nil backoff
+// retries immediately, panics are only retried with WithPanicRetry.
+func WithRetries[T any](max int, backoff func(attempt int) time.Duration) Option[T] {
+	return func(p *Pool[T]) error {
+		if max < 0 {
+			return fmt.Errorf("%w: negative number of retries", ErrInvalidOption)
+		}
+		p.retries = max
+		p.backoff = backoff
+		return nil
+	}
+}
+
+// Only retry errors for which retry reports true, other errors fail the job
+// immediately. Takes effect together with WithRetries. A panic in retry is
+// passed to the panic handler and the error is not retried.
+func WithRetryIf[T any](retry func(err error) bool) Option[T] {
+	return func(p *Pool[T]) error {
+		if retry == nil {
+			return fmt.Errorf("%w: nil retry predicate", ErrInvalidOption)
+		}
+		p.retryIf = retry
+		return nil
+	}
+}
+
+// Predicate for WithRetryIf that retries errors matching any of targets
+// according to errors.Is, for example RetryOn(context.DeadlineExceeded)
+func RetryOn(targets ...error) func(err error) bool {
+	return func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Execute a job that panicked up to max more times on the same worker
+// before recording the panic, for panics caused by transient conditions.
+// Only the panic of the last attempt is passed to the panic handler and
+// counts as a failure, Result.Attempts counts every attempt.
+func WithPanicRetry[T any](max int) Option[T] {
+	return func(p *Pool[T]) error {
+		if max < 0 {
+			return fmt.Errorf("%w: negative number of panic retries", ErrInvalidOption)
+		}
+		p.panicRetries = max
+		return nil
+	}
+}
+
+// Report a panicking job as a result with a *PanicError instead of
+// recording it with the panics
+func WithPanicsAsErrors[T any]() Option[T] {
+	return func(p *Pool[T]) error {
+		p.panicsAsErrors = true
+		return nil
+	}
+}
+
+// Let Wait panic with a *PropagatedPanic holding the first panic recorded
+// from a job once every job finished, for programs in which a panicking job
+// is a bug that should crash them. The results are collected before, so
+// Stats, Panics and Summary still report every job.
+func WithPropagatePanics[T any]() Option[T] {
+	return func(p *Pool[T]) error {
+		p.propagatePanics = true
+		return nil
+	}
+}
+
+// Call handler on the worker goroutine for every panic recovered from a
+// job, before the panic is recorded. A panic in handler is recovered and
+// recorded in PanicInfo.HandlerPanic of the job.
+func WithPanicHandler[T any](handler func(PanicInfo)) Option[T] {
+	return func(p *Pool[T]) error {
+		if handler == nil {
+			return fmt.Errorf("%w: nil panic handler", ErrInvalidOption)
+		}
+		p.panicHandler = handler
+		return nil
+	}
+}
+
+// Run the jobs one at a time on the goroutine that starts the pool, for
+// reproducible tests. Jobs run in the order they would be handed to a
+// worker, which is the order they were added unless they have priorities or
+// dependencies. Run only returns once the pool is done, so Results must be
+// consumed from another goroutine. Cannot be combined with
+// WithDynamicSubmission or with feeding a running pool through
+// RunFromChannel and the like, and jobs cannot weigh more than one worker.
+func WithSerial[T any]() Option[T] {
+	return func(p *Pool[T]) error {
+		p.serial = true
+		return nil
+	}
+}
+
+// Keep accepting jobs through Submit after the pool started until Close is
+// called, Wait blocks until then
+func WithDynamicSubmission[T any]() Option[T] {
+	return func(p *Pool[T]) error {
+		p.dynamic = true
+		return nil
+	}
+}
+
+// Skip a job with ErrAcquiringSemaphore if no worker becomes free for it
+// within d, in which case the pool moves on to the next job. Zero, the
+// default, waits as long as the pool runs.
+func WithAcquireTimeout[T any](d time.Duration) Option[T] {
+	return func(p *Pool[T]) error {
+		if d < 0 {
+			return fmt.Errorf("%w: negative acquire timeout", ErrInvalidOption)
+		}
+		p.acquireTimeout = d
+		return nil
+	}
+}
+
+// Hold at most n jobs waiting to be started. Submit blocks while the queue
+// of a running pool is full, while AddJob and friends return ErrQueueFull
+// and TryAddJob reports false.
+func WithQueueSize[T any](n uint) Option[T] {
+	return func(p *Pool[T]) error {
+		if n == 0 {
+			return fmt.Errorf("%w: queue size must be positive", ErrInvalidOption)
+		}
+		p.queueSize = int(n)
+		return nil
+	}
+}
+
+// Do not keep the results of jobs, for jobs that only matter for their side
+// effects. Wait returns no results but still the panics, and the outcome of
+// the jobs is only counted in Stats. Results of a handle are still set.
+func WithDiscardResults[T any]() Option[T] {
+	return func(p *Pool[T]) error {
+		p.discardResults = true
+		return nil
+	}
+}
+
+// Hand up to n queued jobs to a worker at once, which runs them one after
+// the other and records their results together. Saves scheduling overhead
+// for many small jobs. Only jobs of weight 1 are batched, a batch takes a
+// single worker.
+func WithBatchSize[T any](n uint) Option[T] {
+	return func(p *Pool[T]) error {
+		if n == 0 {
+			return fmt.Errorf("%w: batch size must be positive", ErrInvalidOption)
+		}
+		p.batchSize = int(n)
+		return nil
+	}
+}
+
+// Let workers that had nothing to do for d exit, they are started again
+// when jobs arrive. One worker always stays. Meant for long lived pools
+// created WithDynamicSubmission.
+func WithIdleTimeout[T any](d time.Duration) Option[T] {
+	return func(p *Pool[T]) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: idle timeout must be positive", ErrInvalidOption)
+		}
+		p.idleTimeout = d
+		return nil
+	}
+}
+
+// Stop scheduling new jobs once a job returned an error or panicked, jobs
+// that never started are reported with ErrFailFastTriggered
+func WithFailFast[T any]() Option[T] {
+	return func(p *Pool[T]) error {
+		p.failFast = true
+		return nil
+	}
+}
+
+// Behave like errgroup.WithContext: the first job that returns an error or
+// panics cancels the context of the run with its *JobError as the cause,
+// and WaitErr returns only that error. Like WithFailFast the jobs that did
+// not start yet are skipped with ErrFailFastTriggered, while the errors of
+// running jobs that see the cancellation are recorded in their results.
+func WithFirstError[T any]() Option[T] {
+	return func(p *Pool[T]) error {
+		p.failFast = true
+		p.firstError = true
+		return nil
+	}
+}
+
+// Stop scheduling new jobs once n jobs returned an error or panicked, jobs
+// that never started are reported with ErrTooManyErrors. Jobs that were
+// already handed to a worker still run, so up to MaxWorkers jobs may start
+// after the limit was reached.
+func WithMaxErrors[T any](n uint) Option[T] {
+	return func(p *Pool[T]) error {
+		if n == 0 {
+			return fmt.Errorf("%w: max errors must be positive", ErrInvalidOption)
+		}
+		p.maxErrors = n
+		return nil
+	}
+}
+
+// Start at most n jobs per period, allowing bursts of up to burst jobs.
+// The limit applies before a job waits for a worker. The time between two
+// jobs, per divided by n, must be at least a nanosecond.
+func WithRateLimit[T any](n int, per time.Duration, burst int) Option[T] {
+	return func(p *Pool[T]) error {
+		if n < 1 || per <= 0 || burst < 1 {
+			return fmt.Errorf("%w: rate limit needs a positive rate and burst", ErrInvalidOption)
+		}
+		if per/time.Duration(n) == 0 {
+			return fmt.Errorf("%w: rate limit of %d jobs per %v is finer than a nanosecond", ErrInvalidOption, n, per)
+		}
+		p.limiter = rate.NewLimiter(rate.Every(per/time.Duration(n)), burst)
+		return nil
+	}
+}
+
+// Cancel the context of every job after timeout and report jobs that run
+// longer with ErrJobTimeout. A job that ignores its context keeps its
+// worker until it returns, unless WithDetachOnTimeout is used.
+func WithJobTimeout[T any](timeout time.Duration) Option[T] {
+	return func(p *Pool[T]) error {
+		if timeout <= 0 {
+			return fmt.Errorf("%w: job timeout must be positive", ErrInvalidOption)
+		}
+		p.jobTimeout = timeout
+		return nil
+	}
+}
+
+// Free the worker of a job that timed out without waiting for it to return.
+// Go cannot kill a goroutine, so a job that ignores its context keeps
+// running in the background where its outcome is discarded, while the pool
+// starts another job in its place.
+func WithDetachOnTimeout[T any]() Option[T] {
+	return func(p *Pool[T]) error {
+		p.detachOnTimeout = true
+		return nil
+	}
+}
+
+// Call hook on the worker goroutine right before a job starts. A panic in
+// the hook is passed to the panic handler and the job still runs.
+func WithOnJobStart[T any](hook func(index int)) Option[T] {
+	return func(p *Pool[T]) error {
+		if hook == nil {
+			return fmt.Errorf("%w: nil job start hook", ErrInvalidOption)
+		}
+		p.onJobStart = hook
+		return nil
+	}
+}
+
+// Call hook on the worker goroutine right after a job returned or
+// panicked, before its result is recorded. A panicked job has Panic set on
+// its result, or a *PanicError when panics are converted to errors. A panic
+// in the hook is passed to the panic handler and the result is still
+// recorded.
+func WithOnJobEnd[T any](hook func(index int, result Result[T])) Option[T] {
+	return func(p *Pool[T]) error {
+		if hook == nil {
+			return fmt.Errorf("%w: nil job end hook", ErrInvalidOption)
+		}
+		p.onJobEnd = hook
+		return nil
+	}
+}
+
+// Call hook on the worker goroutine once a job failed with an error, after
+// its retries were used up, with the *JobError of the job. It is not
+// called for jobs that succeeded, panicked or were skipped. A panic in the
+// hook is passed to the panic handler and the result is still recorded.
+func WithOnError[T any](hook func(index int, name string, err error)) Option[T] {
+	return func(p *Pool[T]) error {
+		if hook == nil {
+			return fmt.Errorf("%w: nil error hook", ErrInvalidOption)
+		}
+		p.onError = hook
+		return nil
+	}
+}
+
+// Run every job with the pprof labels pool, set WithName, and job, the
+// name of the job, so CPU profiles attribute samples to jobs. Goroutines
+// started by a job inherit its labels.
+func WithPprofLabels[T any]() Option[T] {
+	return func(p *Pool[T]) error {
+		p.pprofLabels = true
+		return nil
+	}
+}
+
+// Call fn once for every job still running after d, with the time it ran
+// for so far, without stopping it. Jobs that ran for at least d are
+// reported with Slow set whether fn was called or not. fn runs on its own
+// goroutine and the job only ends once it returned.
+func WithSlowJobThreshold[T any](d time.Duration, fn func(index int, name string, elapsed time.Duration)) Option[T] {
+	return func(p *Pool[T]) error {
+		if d <= 0 {
+			return fmt.Errorf("%w: slow job threshold must be positive", ErrInvalidOption)
+		}
+		if fn == nil {
+			return fmt.Errorf("%w: nil slow job hook", ErrInvalidOption)
+		}
+		p.slowThreshold, p.onSlow = d, fn
+		return nil
+	}
+}
+
+// Derive the context of every job from the run context with start, given
+// the index and name of the job. start returns a function that is called
+// with the result once the job ended. Meant for integrations like tracing
+// that start a span per job.
+func WithJobContext[T any](start func(ctx context.Context, index int, name string) (context.Context, func(Result[T]))) Option[T] {
+	return func(p *Pool[T]) error {
+		if start == nil {
+			return fmt.Errorf("%w: nil job context", ErrInvalidOption)
+		}
+		p.jobContext = start
+		return nil
+	}
+}
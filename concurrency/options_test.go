@@ -0,0 +1,424 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"runtime/pprof"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+	"github.com/demy076/typed_goroutines/concurrency/typedpooltest"
+)
+
+func TestWithRateLimitRejectsInvalidRates(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		n     int
+		per   time.Duration
+		burst int
+	}{
+		{"no jobs", 0, time.Second, 1},
+		{"no period", 10, 0, 1},
+		{"no burst", 10, time.Second, 0},
+		{"finer than a nanosecond", 10, 5 * time.Nanosecond, 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := typed_goroutine.NewPoolWithOptions(typed_goroutine.WithRateLimit[int](tc.n, tc.per, tc.burst))
+			if !errors.Is(err, typed_goroutine.ErrInvalidOption) {
+				t.Fatalf("got %v, want ErrInvalidOption", err)
+			}
+		})
+	}
+}
+
+func TestJobTimeout(t *testing.T) {
+	clock := typedpooltest.NewClock(time.Unix(0, 0))
+	pool := typed_goroutine.NewPool[int](2, 2,
+		typed_goroutine.WithClock[int](clock),
+		typed_goroutine.WithJobTimeout[int](time.Minute),
+	)
+	started := make(chan struct{})
+	if _, err := pool.AddJobCtx(func(ctx context.Context) (*int, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	fast, err := pool.AddJob(func() (*int, error) {
+		value := 1
+		return &value, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	<-fast.Done()
+	clock.Advance(time.Minute)
+	results, _ := pool.Wait()
+	if !errors.Is(results[0].Error, typed_goroutine.ErrJobTimeout) {
+		t.Fatalf("slow job: got %v, want ErrJobTimeout", results[0].Error)
+	}
+	if results[1].Error != nil || *results[1].Result != 1 {
+		t.Fatalf("fast job: got %v, %v, want 1", results[1].Result, results[1].Error)
+	}
+}
+
+func TestDetachOnTimeout(t *testing.T) {
+	clock := typedpooltest.NewClock(time.Unix(0, 0))
+	pool := typed_goroutine.NewPool[int](2, 1,
+		typed_goroutine.WithClock[int](clock),
+		typed_goroutine.WithJobTimeout[int](time.Minute),
+		typed_goroutine.WithDetachOnTimeout[int](),
+	)
+	started, stuck := make(chan struct{}), make(chan struct{})
+	defer close(stuck)
+	// Ignores its context, so only detaching frees the single worker
+	if _, err := pool.AddJob(func() (*int, error) {
+		close(started)
+		<-stuck
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.AddJob(noop); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	clock.Advance(time.Minute)
+	results, _ := pool.Wait()
+	if !errors.Is(results[0].Error, typed_goroutine.ErrJobTimeout) {
+		t.Fatalf("stuck job: got %v, want ErrJobTimeout", results[0].Error)
+	}
+	if results[1].Error != nil {
+		t.Fatalf("job after the stuck one: got %v, want no error", results[1].Error)
+	}
+}
+
+func TestIdleWorkersRetire(t *testing.T) {
+	clock := typedpooltest.NewClock(time.Unix(0, 0))
+	var teardowns atomic.Int32
+	pool := typed_goroutine.NewPool[int](0, 4,
+		typed_goroutine.WithClock[int](clock),
+		typed_goroutine.WithDynamicSubmission[int](),
+		typed_goroutine.WithIdleTimeout[int](time.Minute),
+		typed_goroutine.WithWorkerState[int](func(id int) (int, error) { return id, nil }, func(int) {
+			teardowns.Add(1)
+		}),
+	)
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	// Every job waits for the others, so the burst takes all four workers
+	var started atomic.Int32
+	burst := func(round int) []*typed_goroutine.JobHandle[int] {
+		var handles []*typed_goroutine.JobHandle[int]
+		for i := range 4 {
+			handle, err := pool.Submit(func() (*int, error) {
+				started.Add(1)
+				for started.Load() < int32(4*round) {
+					runtime.Gosched()
+				}
+				value := 10*round + i
+				return &value, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			handles = append(handles, handle)
+		}
+		return handles
+	}
+	for _, handle := range burst(1) {
+		<-handle.Done()
+	}
+	goroutines := runtime.NumGoroutine()
+	// A worker still finishing its job when the clock moves resets its
+	// timer, so keep moving it until three retired. The last one stays.
+	waitFor(t, func() bool {
+		clock.Advance(time.Minute)
+		return teardowns.Load() == 3
+	})
+	waitFor(t, func() bool { return runtime.NumGoroutine() <= goroutines-3 })
+
+	handles := burst(2)
+	pool.Close()
+	pool.Wait()
+	for i, handle := range handles {
+		result, _ := handle.Result()
+		if result.Error != nil || *result.Result != 20+i {
+			t.Fatalf("job %d after going idle: got %v, %v, want %d", i, result.Result, result.Error, 20+i)
+		}
+	}
+	// The three retired workers and the four running the second burst
+	if got := teardowns.Load(); got != 7 {
+		t.Fatalf("got %d worker teardowns, want 7", got)
+	}
+}
+
+func TestDeadlineBeforeRun(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](3, 1, typed_goroutine.WithDeadline[int](time.Now().Add(-time.Second)))
+	for range 3 {
+		if _, err := pool.AddJob(noop); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, _ := pool.Wait()
+	for _, result := range results {
+		if !result.Skipped || !errors.Is(result.Error, typed_goroutine.ErrPoolDeadlineExceeded) {
+			t.Fatalf("job %d: got %v, want it skipped with ErrPoolDeadlineExceeded", result.Index, result.Error)
+		}
+	}
+}
+
+func TestDeadlineMidRun(t *testing.T) {
+	clock := typedpooltest.NewClock(time.Unix(0, 0))
+	pool := typed_goroutine.NewPool[int](3, 1,
+		typed_goroutine.WithClock[int](clock),
+		typed_goroutine.WithTimeout[int](time.Minute),
+	)
+	started := make(chan struct{})
+	if _, err := pool.AddJobCtx(func(ctx context.Context) (*int, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, context.Cause(ctx)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := pool.AddJob(noop); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	clock.Advance(time.Minute)
+	results, _ := pool.Wait()
+	if results[0].Skipped || !errors.Is(results[0].Error, typed_goroutine.ErrPoolDeadlineExceeded) {
+		t.Fatalf("running job: got %v, want its context cancelled with ErrPoolDeadlineExceeded", results[0].Error)
+	}
+	for _, result := range results[1:] {
+		if !result.Skipped || !errors.Is(result.Error, typed_goroutine.ErrPoolDeadlineExceeded) {
+			t.Fatalf("job %d: got %v, want it skipped with ErrPoolDeadlineExceeded", result.Index, result.Error)
+		}
+	}
+	stats := pool.Stats()
+	if stats.Completed+stats.Failed+stats.Skipped != stats.Total {
+		t.Fatalf("%d completed, %d failed and %d skipped of %d jobs", stats.Completed, stats.Failed, stats.Skipped, stats.Total)
+	}
+}
+
+func TestDeadlineAfterCompletion(t *testing.T) {
+	clock := typedpooltest.NewClock(time.Unix(0, 0))
+	pool := typed_goroutine.NewPool[int](2, 1,
+		typed_goroutine.WithClock[int](clock),
+		typed_goroutine.WithTimeout[int](time.Minute),
+	)
+	for range 2 {
+		if _, err := pool.AddJob(noop); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pool.Wait()
+	clock.Advance(time.Minute)
+	results, _ := pool.Wait()
+	for _, result := range results {
+		if result.Error != nil {
+			t.Fatalf("job %d: got %v, want no error", result.Index, result.Error)
+		}
+	}
+	if halted := pool.Stats().Halted; halted != nil {
+		t.Fatalf("pool halted with %v after it finished", halted)
+	}
+}
+
+type tenantKey struct{}
+
+func TestBaseContext(t *testing.T) {
+	errGone := errors.New("tenant gone")
+	base, cancel := context.WithCancelCause(context.WithValue(context.Background(), tenantKey{}, "acme"))
+	pool := typed_goroutine.NewPool[string](3, 1, typed_goroutine.WithBaseContext[string](base))
+	tenants := make(chan string, 1)
+	if _, err := pool.AddJobCtx(func(ctx context.Context) (*string, error) {
+		tenants <- ctx.Value(tenantKey{}).(string)
+		<-ctx.Done()
+		return nil, context.Cause(ctx)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := pool.AddJob(func() (*string, error) { return nil, nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if tenant := <-tenants; tenant != "acme" {
+		t.Fatalf("job saw tenant %q, want acme", tenant)
+	}
+	cancel(errGone)
+	results, _ := pool.Wait()
+	if !errors.Is(results[0].Error, errGone) {
+		t.Fatalf("running job: got %v, want the cause of the base context", results[0].Error)
+	}
+	for _, result := range results[1:] {
+		if !result.Skipped {
+			t.Fatalf("job %d started after the base context was cancelled", result.Index)
+		}
+	}
+}
+
+// WithFirstError skips the jobs not started yet like WithFailFast, but also
+// cancels the running ones and returns only the first error
+func TestFirstErrorAgainstFailFast(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		opt    typed_goroutine.Option[int]
+		cancel bool
+	}{
+		{"fail fast", typed_goroutine.WithFailFast[int](), false},
+		{"first error", typed_goroutine.WithFirstError[int](), true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := typed_goroutine.NewPool[int](4, 2, tc.opt)
+			errFirst := errors.New("first")
+			started, release := make(chan struct{}), make(chan struct{})
+			if _, err := pool.AddJobCtx(func(ctx context.Context) (*int, error) {
+				close(started)
+				select {
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				case <-release:
+					return nil, nil
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := pool.AddJob(func() (*int, error) {
+				<-started
+				return nil, errFirst
+			}); err != nil {
+				t.Fatal(err)
+			}
+			for range 2 {
+				if _, err := pool.AddJob(noop); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := pool.Run(); err != nil {
+				t.Fatal(err)
+			}
+			if !tc.cancel {
+				// Fail fast leaves the running job alone
+				waitFor(t, func() bool { return pool.Stats().Failed == 1 })
+				close(release)
+			}
+			results, err := pool.WaitErr()
+			var jobErr *typed_goroutine.JobError
+			if !errors.As(err, &jobErr) || jobErr.Index != 1 || !errors.Is(err, errFirst) {
+				t.Fatalf("got %v, want it to carry the error of job 1", err)
+			}
+			if cancelled := errors.Is(results[0].Error, context.Canceled); cancelled != tc.cancel || results[0].Skipped {
+				t.Fatalf("running job got %v, want it cancelled %v and not skipped", results[0].Error, tc.cancel)
+			}
+			for _, result := range results[2:] {
+				if !result.Skipped || !errors.Is(result.Error, typed_goroutine.ErrFailFastTriggered) {
+					t.Fatalf("job %d got %v, want it skipped with ErrFailFastTriggered", result.Index, result.Error)
+				}
+			}
+			// Only the first error is returned, not the skipped jobs or the
+			// cancelled one
+			if joined := errors.Is(err, typed_goroutine.ErrFailFastTriggered); joined == tc.cancel {
+				t.Fatalf("error %v joins the skipped jobs: %v, want %v", err, joined, !tc.cancel)
+			}
+			if tc.cancel && errors.Is(err, context.Canceled) {
+				t.Fatalf("error %v joins the cancelled job", err)
+			}
+		})
+	}
+}
+
+// A job that finds no free worker in time is skipped, and the pool moves on
+// to the next job instead of skipping the rest for the same reason
+func TestAcquireTimeout(t *testing.T) {
+	clock := typedpooltest.NewClock(time.Unix(0, 0))
+	pool := typed_goroutine.NewPool[int](3, 1,
+		typed_goroutine.WithClock[int](clock),
+		typed_goroutine.WithAcquireTimeout[int](time.Second),
+	)
+	started, release := make(chan struct{}), make(chan struct{})
+	if _, err := pool.AddJob(func() (*int, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := pool.AddJob(noop); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	// Job 1 waits for the only worker, which job 0 holds
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	// Job 2 waits in its place with a timeout of its own
+	waitFor(t, func() bool { return pool.Stats().Skipped == 1 })
+	clock.BlockUntil(1)
+	close(release)
+	results, _ := pool.Wait()
+	if len(results) != 3 || !results[0].OK || !results[2].OK {
+		t.Fatalf("got %+v, want jobs 0 and 2 to succeed", results)
+	}
+	var jobErr *typed_goroutine.JobError
+	if err := results[1].Error; !results[1].Skipped || !errors.Is(err, typed_goroutine.ErrAcquiringSemaphore) ||
+		!errors.As(err, &jobErr) || jobErr.Index != 1 {
+		t.Fatalf("job 1 got %v, want it skipped with ErrAcquiringSemaphore", err)
+	}
+}
+
+func TestPprofLabels(t *testing.T) {
+	for _, labelled := range []bool{false, true} {
+		opts := []typed_goroutine.Option[string]{typed_goroutine.WithName[string]("crawl")}
+		if labelled {
+			opts = append(opts, typed_goroutine.WithPprofLabels[string]())
+		}
+		pool := typed_goroutine.NewPool[string](2, 2, opts...)
+		for range 2 {
+			if _, err := pool.AddJobCtx(func(ctx context.Context) (*string, error) {
+				poolLabel, _ := pprof.Label(ctx, "pool")
+				jobLabel, _ := pprof.Label(ctx, "job")
+				labels := poolLabel + "/" + jobLabel
+				return &labels, nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		results, _ := pool.Wait()
+		for _, result := range results {
+			want := "/"
+			if labelled {
+				want = "crawl/" + result.Name
+			}
+			if got := *result.Result; got != want {
+				t.Errorf("labelled %v: job %d got labels %q, want %q", labelled, result.Index, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package typed_goroutine
+
+import (
+	"cmp"
+	"slices"
+)
+
+// Stream results like Results, but in job order: the result of job i is
+// delivered once those of all jobs before it were. Results that arrive
+// early are buffered without bound so workers never wait for a slow job,
+// at worst all but the first job finished and their results are held until
+// it does. If a result never arrives, like that of a job abandoned by
+// Drain, the results after it are delivered in job order once the pool is
+// done. Must be called before Run instead of Results.
+func (p *Pool[T]) OrderedResults() <-chan Result[T] {
+	return reorder(p.Results(), p.retried)
+}
+
+// Forward results in index order starting at 0, the values emitted by a
+// streaming job before its final result. The indexes a pool created by
+// RetryFailed starts with come first, the jobs added later follow them.
+func reorder[T any](in <-chan Result[T], retried []int) <-chan Result[T] {
+	out := make(chan Result[T])
+	after := func(index int) int {
+		if i, ok := slices.BinarySearch(retried, index); ok && i+1 < len(retried) {
+			return retried[i+1]
+		}
+		return index + 1
+	}
+	// Position of a job in the order results are forwarded in
+	rank := func(index int) int {
+		if i, ok := slices.BinarySearch(retried, index); ok {
+			return i
+		}
+		return len(retried) + index
+	}
+	go func() {
+		defer close(out)
+		early := make(map[int][]Result[T])
+		next := 0
+		if len(retried) > 0 {
+			next = retried[0]
+		}
+		for result := range in {
+			if result.Index != next {
+				early[result.Index] = append(early[result.Index], result)
+				continue
+			}
+			out <- result
+			if result.partial {
+				continue
+			}
+			// Pass on what arrived for the following jobs until one of
+			// them is still running
+			for next = after(next); ; next = after(next) {
+				buffered := early[next]
+				delete(early, next)
+				for _, ready := range buffered {
+					out <- ready
+				}
+				if len(buffered) == 0 || buffered[len(buffered)-1].partial {
+					break
+				}
+			}
+		}
+		// Results held back by a job whose result never came, in job order
+		held := make([]int, 0, len(early))
+		for index := range early {
+			held = append(held, index)
+		}
+		slices.SortFunc(held, func(a, b int) int { return cmp.Compare(rank(a), rank(b)) })
+		for _, index := range held {
+			for _, ready := range early[index] {
+				out <- ready
+			}
+		}
+	}()
+	return out
+}
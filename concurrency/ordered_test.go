@@ -0,0 +1,52 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+// Drain abandons job 0, whose result may then be dropped once it returns.
+// The results held back behind it are delivered either way.
+func TestOrderedResultsAfterGap(t *testing.T) {
+	for range 50 {
+		pool := typed_goroutine.NewPool[int](4, 4)
+		release := make(chan struct{})
+		if _, err := pool.AddJob(func() (*int, error) {
+			<-release
+			return nil, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		for i := 1; i < 4; i++ {
+			if _, err := pool.AddJobV(func() (int, error) { return i, nil }); err != nil {
+				t.Fatal(err)
+			}
+		}
+		ordered := pool.OrderedResults()
+		if err := pool.Run(); err != nil {
+			t.Fatal(err)
+		}
+		waitFor(t, func() bool { return pool.Stats().Completed == 3 })
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		var abandoned *typed_goroutine.AbandonedError
+		if _, _, err := pool.Drain(ctx); !errors.As(err, &abandoned) {
+			t.Fatalf("got %v, want job 0 abandoned", err)
+		}
+		close(release)
+		var indexes []int
+		for result := range ordered {
+			indexes = append(indexes, result.Index)
+		}
+		if len(indexes) == 0 || indexes[0] != 0 {
+			indexes = append([]int{0}, indexes...)
+		}
+		if want := []int{0, 1, 2, 3}; !slices.Equal(indexes, want) {
+			t.Fatalf("got results of jobs %v, want %v with or without job 0", indexes, want)
+		}
+	}
+}
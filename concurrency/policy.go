@@ -0,0 +1,71 @@
+package typed_goroutine
+
+import "fmt"
+
+// How failures of a job are handled, overriding the options of the pool for
+// jobs added with AddJobWithPolicy or to a group WithGroupPolicy
+type Policy struct {
+	// Reported in Result.Policy
+	Name string
+	// Number of times an error of the job is retried instead of as set
+	// WithRetries, with the backoff and WithRetryIf of the pool
+	Retries int
+	// Whether a failure of the job skips the jobs that did not start yet
+	// like WithFailFast, regardless of whether the pool fails fast
+	FailFast bool
+	// Whether failures of the job are ignored, they never make the pool
+	// fail fast and do not count towards WithMaxErrors, WithFirstError or
+	// JoinCancelOnFailure
+	IgnoreFailures bool
+}
+
+func (policy Policy) validate() error {
+	if policy.Retries < 0 {
+		return fmt.Errorf("%w: negative number of retries in policy %q", ErrInvalidOption, policy.Name)
+	}
+	if policy.FailFast && policy.IgnoreFailures {
+		return fmt.Errorf("%w: policy %q both fails fast and ignores failures", ErrInvalidOption, policy.Name)
+	}
+	return nil
+}
+
+// Add a job whose failures are handled by policy rather than the options
+// of the pool. A job of a group WithGroupPolicy keeps its own policy.
+func (p *Pool[T]) AddJobWithPolicy(job func() (*T, error), policy Policy) (*JobHandle[T], error) {
+	if err := policy.validate(); err != nil {
+		return nil, err
+	}
+	return p.add(queuedJob[T]{plain: job, policy: &policy}, false)
+}
+
+// Handle the failures of the jobs added to group with policy, as if every
+// one of them was added with AddJobWithPolicy
+func WithGroupPolicy[T any](group string, policy Policy) Option[T] {
+	return func(p *Pool[T]) error {
+		if group == "" {
+			return fmt.Errorf("%w: empty group name", ErrInvalidOption)
+		}
+		if err := policy.validate(); err != nil {
+			return err
+		}
+		if p.policies == nil {
+			p.policies = make(map[string]*Policy)
+		}
+		p.policies[group] = &policy
+		return nil
+	}
+}
+
+// Whether a failure of the job counts towards the failures of the pool. A
+// job run by Execute does not count either.
+func (job queuedJob[T]) counts() bool {
+	return !job.single && (job.policy == nil || !job.policy.IgnoreFailures)
+}
+
+// Name of the policy of the job, empty if it has none
+func (job queuedJob[T]) policyName() string {
+	if job.policy == nil {
+		return ""
+	}
+	return job.policy.Name
+}
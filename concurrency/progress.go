@@ -0,0 +1,82 @@
+package typed_goroutine
+
+import "fmt"
+
+// Progress of a running pool
+type Progress struct {
+	// Jobs added to the pool so far
+	Total int
+	// Jobs that finished, failed, panicked or were skipped. Reaches Total
+	// once the pool is done.
+	Completed int
+	// Completed jobs that returned an error or panicked
+	Failed int
+	// Completed jobs that were never started
+	Skipped int
+	// Jobs currently running
+	Running int
+}
+
+// Call fn with the progress of the pool as jobs complete, including panics
+// and skipped jobs. fn runs on its own goroutine and is never called
+// concurrently with itself. A slow fn does not hold up the workers: updates
+// that arrive while it runs are coalesced, so it may skip intermediate
+// counts but always sees the latest ones, and the final call with
+// Completed equal to Total happens before Wait returns.
+func WithProgress[T any](fn func(Progress)) Option[T] {
+	return func(p *Pool[T]) error {
+		if fn == nil {
+			return fmt.Errorf("%w: nil progress callback", ErrInvalidOption)
+		}
+		p.onProgress = fn
+		return nil
+	}
+}
+
+// Current progress, the caller must hold the lock of the pool
+func (p *Pool[T]) progress() Progress {
+	return Progress{
+		Total:     p.added,
+		Completed: p.finished,
+		Failed:    int(p.counters.failed.Load() + p.counters.panicked.Load()),
+		Skipped:   int(p.counters.skipped.Load()),
+		Running:   int(p.counters.running.Load()),
+	}
+}
+
+// Tell the progress goroutine something changed without blocking, the
+// caller must hold the lock of the pool
+func (p *Pool[T]) notifyProgress() {
+	if p.progressed == nil {
+		return
+	}
+	select {
+	case p.progressed <- struct{}{}:
+	default:
+	}
+}
+
+// Report progress until the pool is done, returns a function that stops
+// reporting after a final call
+func (p *Pool[T]) reportProgress() (stop func()) {
+	progressed := make(chan struct{}, 1)
+	stopped := make(chan struct{})
+	p.progressed = progressed
+	go func() {
+		defer close(stopped)
+		for range progressed {
+			p.mu.Lock()
+			progress := p.progress()
+			p.mu.Unlock()
+			p.onProgress(progress)
+		}
+	}()
+	return func() {
+		p.mu.Lock()
+		p.notifyProgress()
+		p.progressed = nil
+		p.mu.Unlock()
+		close(progressed)
+		<-stopped
+	}
+}
@@ -0,0 +1,152 @@
+package typed_goroutine
+
+import (
+	"context"
+	"time"
+)
+
+// A job waiting to be started
+type queuedJob[T any] struct {
+	index int
+	name  string
+	group string
+	// Jobs of the same key are run once while one of them is unfinished
+	key string
+	// Indexes of the jobs that must succeed before this one starts
+	deps     []int
+	priority int
+	// Turn of the job among the jobs of its group WithFairness, and its
+	// place in the order jobs of the same round start
+	round   int
+	order   uint64
+	weight  int64
+	timeout time.Duration
+	// Time to wait before queueing the job and when it is due
+	delay time.Duration
+	due   time.Time
+	// Waits in the delay queue until it is released, see Then
+	held    bool
+	addedAt time.Time
+	fn      func(ctx context.Context) (*T, error)
+	// Set instead of fn for jobs that do not observe the context, which
+	// saves wrapping every one of them
+	plain func() (*T, error)
+	// Job of AddJobRepeated, this is its iteration with repeats more
+	// to follow
+	iterate   func(iteration int) (*T, error)
+	iteration int
+	repeats   int
+	// Run by Execute rather than as a job of the pool
+	single bool
+	// Yielded its worker and is queued again, see Continue
+	resumed bool
+	// Job of AddStreamingJob, its results are emitted while it runs
+	streaming bool
+	// Job of AddJobRunner, fn runs it
+	runner Job[T]
+	// Handles failures of the job instead of the options of the pool
+	policy *Policy
+	// Set instead of fn for jobs returning a value
+	value  func() (T, error)
+	handle *JobHandle[T]
+}
+
+// Jobs waiting to be started ordered by priority, then by round, then by
+// order, which is the index unless shuffled. Jobs that yielded come after
+// the other jobs of their round in the order they yielded. It is a heap like
+// container/heap maintains, without boxing every job in an interface.
+type jobQueue[T any] []queuedJob[T]
+
+func (q jobQueue[T]) less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	if q[i].round != q[j].round {
+		return q[i].round < q[j].round
+	}
+	if q[i].resumed != q[j].resumed {
+		return q[j].resumed
+	}
+	return q[i].order < q[j].order
+}
+
+// Handles track their position so that cancelled jobs can be removed
+func (q jobQueue[T]) swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].handle.pos = i
+	q[j].handle.pos = j
+}
+
+func (q *jobQueue[T]) push(job queuedJob[T]) {
+	q.insert(job, jobQueue[T].less)
+}
+
+func (q *jobQueue[T]) pop() queuedJob[T] {
+	return q.removeAt(0, jobQueue[T].less)
+}
+
+func (q *jobQueue[T]) remove(pos int) queuedJob[T] {
+	return q.removeAt(pos, jobQueue[T].less)
+}
+
+// Add a job to the heap ordered by less
+func (q *jobQueue[T]) insert(job queuedJob[T], less func(q jobQueue[T], i, j int) bool) {
+	job.handle.pos = len(*q)
+	*q = append(*q, job)
+	q.up(len(*q)-1, less)
+}
+
+// Take the job at pos off the heap ordered by less
+func (q *jobQueue[T]) removeAt(pos int, less func(q jobQueue[T], i, j int) bool) queuedJob[T] {
+	old := *q
+	n := len(old) - 1
+	if n != pos {
+		old.swap(pos, n)
+		if !old[:n].down(pos, less) {
+			old[:n].up(pos, less)
+		}
+	}
+	job := old[n]
+	old[n] = queuedJob[T]{}
+	*q = old[:n]
+	job.handle.pos = -1
+	return job
+}
+
+// Order every job of the heap by less
+func (q jobQueue[T]) init(less func(q jobQueue[T], i, j int) bool) {
+	for i := len(q)/2 - 1; i >= 0; i-- {
+		q.down(i, less)
+	}
+}
+
+func (q jobQueue[T]) up(j int, less func(q jobQueue[T], i, j int) bool) {
+	for j > 0 {
+		i := (j - 1) / 2
+		if !less(q, j, i) {
+			break
+		}
+		q.swap(i, j)
+		j = i
+	}
+}
+
+// Move the job at i down, reports whether it moved
+func (q jobQueue[T]) down(i0 int, less func(q jobQueue[T], i, j int) bool) bool {
+	i := i0
+	for {
+		j := 2*i + 1
+		if j >= len(q) || j < 0 {
+			break
+		}
+		if r := j + 1; r < len(q) && less(q, r, j) {
+			j = r
+		}
+		if !less(q, j, i) {
+			break
+		}
+		q.swap(i, j)
+		i = j
+	}
+	return i > i0
+}
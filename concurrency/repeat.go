@@ -0,0 +1,45 @@
+package typed_goroutine
+
+import (
+	"context"
+	"strconv"
+)
+
+// Add n jobs calling job with their iteration from 0 to n-1, for example
+// to load test an endpoint. Iteration i gets the index of the first one
+// plus i and carries i in Result.Iteration. Only the next iteration is
+// queued at any time, the one after it is created when it starts, so
+// waiting iterations take up no memory. Each iteration runs like a job
+// added with AddJob.
+func (p *Pool[T]) AddJobRepeated(n uint, job func(iteration int) (*T, error)) error {
+	if n == 0 {
+		return nil
+	}
+	_, err := p.add(queuedJob[T]{fn: iterate(job, 0), iterate: job, repeats: int(n - 1)}, false)
+	return err
+}
+
+// Adapt an iteration of a repeated job
+func iterate[T any](job func(iteration int) (*T, error), iteration int) func(context.Context) (*T, error) {
+	return func(context.Context) (*T, error) {
+		return job(iteration)
+	}
+}
+
+// Take the next job off the queue, queueing the next iteration of a
+// repeated job in its place. The caller must hold the lock of the pool.
+func (p *Pool[T]) take() queuedJob[T] {
+	job := p.queue.pop()
+	p.round = max(p.round, job.round)
+	if job.repeats > 0 {
+		next := job
+		next.index++
+		next.iteration++
+		next.repeats--
+		next.name = strconv.Itoa(next.index)
+		next.handle = newJobHandle(p, next.index)
+		next.fn = iterate(job.iterate, next.iteration)
+		p.push(next)
+	}
+	return job
+}
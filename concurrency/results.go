@@ -0,0 +1,100 @@
+package typed_goroutine
+
+import (
+	"sort"
+	"time"
+)
+
+// Results of a pool with helpers to split them, for example
+// Results[T](results).Successes() on the results of Wait
+type Results[T any] []Result[T]
+
+// Values of the jobs that succeeded in the order of the results, a job that
+// returned a nil result contributes the zero value
+func (r Results[T]) Successes() []T {
+	oks, _ := r.Partition()
+	return oks
+}
+
+// Errors of the jobs that failed, panicked or were skipped in the order of
+// the results, each a *JobError carrying the index of its job. A panic is
+// wrapped as a *PanicError. Nil if every job succeeded.
+func (r Results[T]) Failures() []error {
+	var errs []error
+	for _, result := range r {
+		if err := result.err(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Values of the jobs that succeeded and errors of the others, see
+// Successes and Failures
+func (r Results[T]) Partition() (oks []T, errs []error) {
+	for _, result := range r {
+		if err := result.err(); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if result.streamed && !result.partial {
+			continue
+		}
+		if oks == nil {
+			oks = make([]T, 0, len(r))
+		}
+		oks = append(oks, result.Value)
+	}
+	return oks, errs
+}
+
+// Error of a job that did not succeed as a *JobError, nil if it succeeded
+func (r Result[T]) err() error {
+	if r.Panic != nil {
+		return &JobError{
+			Index:   r.Index,
+			Name:    r.Name,
+			Attempt: r.Attempts,
+			Err:     &PanicError{Value: r.Panic.Value, Stack: r.Panic.Stack},
+		}
+	}
+	return r.Error
+}
+
+// Jobs a worker ran and how long they took, see SkewReport
+type WorkerLoad struct {
+	Worker int
+	// Jobs the worker ran, including failed ones and panics
+	Jobs int
+	// Time the jobs of the worker spent running and waiting to start
+	Busy      time.Duration
+	QueueWait time.Duration
+}
+
+// Summarize which worker ran how many of the jobs of results and for how
+// long, ordered by worker ID, to tell uneven work apart from jobs waiting
+// too long for a worker. Jobs that did not run are left out.
+func SkewReport[T any](results []Result[T]) []WorkerLoad {
+	loads := make(map[int]*WorkerLoad)
+	for _, result := range results {
+		if result.Worker < 0 || result.Skipped || result.partial {
+			continue
+		}
+		load, ok := loads[result.Worker]
+		if !ok {
+			load = &WorkerLoad{Worker: result.Worker}
+			loads[result.Worker] = load
+		}
+		load.Jobs++
+		load.Busy += result.Duration
+		load.QueueWait += result.QueueWait
+	}
+	report := make([]WorkerLoad, 0, len(loads))
+	for _, load := range loads {
+		report = append(report, *load)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Worker < report[j].Worker
+	})
+	return report
+}
@@ -0,0 +1,31 @@
+package typed_goroutine_test
+
+import (
+	"testing"
+	"time"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+	"github.com/demy076/typed_goroutines/concurrency/typedpooltest"
+)
+
+func TestSkewReportFromMeta(t *testing.T) {
+	clock := typedpooltest.NewClock(time.Unix(0, 0))
+	pool := typed_goroutine.NewPool[int](2, 1, typed_goroutine.WithClock[int](clock))
+	for range 2 {
+		if _, err := pool.AddJob(func() (*int, error) {
+			clock.Advance(time.Second)
+			return nil, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, _ := pool.Wait()
+	second := results[1].Meta
+	if second.Worker != 0 || second.Attempts != 1 || second.Duration != time.Second || second.QueueWait != time.Second {
+		t.Fatalf("got meta %+v, want worker 0, 1 attempt, 1s running and 1s queued", second)
+	}
+	want := []typed_goroutine.WorkerLoad{{Worker: 0, Jobs: 2, Busy: 2 * time.Second, QueueWait: time.Second}}
+	if got := typed_goroutine.SkewReport(results); len(got) != 1 || got[0] != want[0] {
+		t.Fatalf("got report %+v, want %+v", got, want)
+	}
+}
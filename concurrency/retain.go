@@ -0,0 +1,115 @@
+package typed_goroutine
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Keep every job that failed or was skipped together with its result, so it
+// can be run again with FailedJobs or RetryFailed. Off by default since it
+// holds on to the closures of those jobs and whatever they reference.
+func WithRetainJobs[T any]() Option[T] {
+	return func(p *Pool[T]) error {
+		p.retainJobs = true
+		return nil
+	}
+}
+
+// Keep the jobs that panicked as well WithRetainJobs, which leaves them
+// out by default since running them again likely panics again
+func WithRetainPanickedJobs[T any]() Option[T] {
+	return func(p *Pool[T]) error {
+		p.retainJobs = true
+		p.retainPanics = true
+		return nil
+	}
+}
+
+// Link a result that did not succeed to its job WithRetainJobs
+func (p *Pool[T]) retain(result *Result[T], job queuedJob[T]) {
+	if !p.retainJobs || result.OK {
+		return
+	}
+	retained := new(queuedJob[T])
+	*retained = job
+	result.retained = retained
+}
+
+// Jobs that failed or were skipped ordered by index, once the pool was
+// waited on, including those that panicked WithRetainPanickedJobs. Jobs
+// observing the context run with context.Background. Empty unless the
+// pool was created WithRetainJobs, and without the jobs whose results were
+// streamed.
+func (p *Pool[T]) FailedJobs() []func() (*T, error) {
+	var jobs []func() (*T, error)
+	for _, job := range p.failedJobs() {
+		jobs = append(jobs, func() (*T, error) {
+			return job.call(context.Background())
+		})
+	}
+	return jobs
+}
+
+// Create a pool that is not started yet with the jobs returned by
+// FailedJobs and at most workers goroutines, zero meaning one per CPU. The
+// pool is created with the options of this one, so it has the same name,
+// retries, timeouts, hooks, logger, clock and limiter, and retains its
+// jobs as well, so failures can be retried again. The jobs keep their
+// index and name, as well as their priority, weight, timeout, key and
+// group, and they observe the context of the new pool. Jobs added to it
+// later are numbered after them.
+func (p *Pool[T]) RetryFailed(workers uint) *Pool[T] {
+	failed := p.failedJobs()
+	opts := append(slices.Clip(p.opts), WithWorkers[T](workers), WithRetainJobs[T]())
+	retry := NewPool(uint(len(failed)), workers, opts...)
+	retry.mu.Lock()
+	defer retry.mu.Unlock()
+	for _, job := range failed {
+		// Dependencies and iterations refer to the jobs of this pool
+		job.deps, job.repeats, job.iterate = nil, 0, nil
+		job.delay, job.due = 0, time.Time{}
+		retry.nextIndex = job.index
+		retry.retried = append(retry.retried, job.index)
+		retry.enqueue(job)
+	}
+	return retry
+}
+
+// Retained jobs that failed or were skipped
+func (p *Pool[T]) failedJobs() []queuedJob[T] {
+	var jobs []queuedJob[T]
+	for _, result := range p.collected() {
+		if result.retained == nil || !p.retainPanics && (result.Panic != nil || isPanicError(result.Error)) {
+			continue
+		}
+		jobs = append(jobs, *result.retained)
+	}
+	return jobs
+}
+
+// Whether a job of the pool has the index, a pool created by RetryFailed
+// has gaps between the indexes it started with
+func (p *Pool[T]) hasIndex(index int) bool {
+	if index < 0 || index >= p.nextIndex {
+		return false
+	}
+	if len(p.retried) == 0 || index > p.retried[len(p.retried)-1] {
+		return true
+	}
+	_, ok := slices.BinarySearch(p.retried, index)
+	return ok
+}
+
+// Run a job once on the current goroutine without any of the handling of
+// the pool
+func (job queuedJob[T]) call(ctx context.Context) (*T, error) {
+	switch {
+	case job.plain != nil:
+		return job.plain()
+	case job.value != nil:
+		value, err := job.value()
+		return &value, err
+	}
+	return job.fn(ctx)
+}
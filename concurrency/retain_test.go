@@ -0,0 +1,90 @@
+package typed_goroutine_test
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"testing"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+// Add jobs that fail on their first run if flaky says so, and panic at
+// index panicAt
+func addFlakyJobs(t *testing.T, pool *typed_goroutine.Pool[int], flaky []bool, panicAt int) {
+	t.Helper()
+	for i, fails := range flaky {
+		var runs atomic.Int32
+		if _, err := pool.AddJob(func() (*int, error) {
+			if i == panicAt {
+				panic("bad input")
+			}
+			if fails && runs.Add(1) == 1 {
+				return nil, errors.New("flaky")
+			}
+			return &i, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func indexes(results []typed_goroutine.Result[int]) []int {
+	var indexes []int
+	for _, result := range results {
+		indexes = append(indexes, result.Index)
+	}
+	return indexes
+}
+
+func TestRetryFailedKeepsIndexesAndConfig(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](5, 2,
+		typed_goroutine.WithName[int]("batch"),
+		typed_goroutine.WithRetainJobs[int](),
+	)
+	addFlakyJobs(t, pool, []bool{false, true, false, true, false}, 4)
+	pool.Wait()
+	if jobs := pool.FailedJobs(); len(jobs) != 2 {
+		t.Fatalf("got %d failed jobs, want the 2 that did not panic", len(jobs))
+	}
+	retry := pool.RetryFailed(1)
+	if retry.Name() != "batch" || retry.MaxWorkers() != 1 {
+		t.Fatalf("got pool %q with %d workers, want batch with 1", retry.Name(), retry.MaxWorkers())
+	}
+	results, _ := retry.Wait()
+	if got := indexes(results); !slices.Equal(got, []int{1, 3}) {
+		t.Fatalf("got indexes %v, want 1 and 3", got)
+	}
+	for _, result := range results {
+		if !result.OK || result.Value != result.Index || result.Name != strconv.Itoa(result.Index) {
+			t.Fatalf("job %d (%s) got %d, %v", result.Index, result.Name, result.Value, result.Error)
+		}
+	}
+}
+
+func TestRetryFailedWithPanickedJobs(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](3, 2, typed_goroutine.WithRetainPanickedJobs[int]())
+	addFlakyJobs(t, pool, []bool{true, false, false}, 2)
+	pool.Wait()
+	retry := pool.RetryFailed(2)
+	// Added after the jobs retried, so numbered after them
+	later, err := retry.AddJob(func() (*int, error) { return nil, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if later.Index() != 3 {
+		t.Fatalf("job added later got index %d, want 3", later.Index())
+	}
+	var got []int
+	ordered := retry.OrderedResults()
+	if err := retry.Run(); err != nil {
+		t.Fatal(err)
+	}
+	for result := range ordered {
+		got = append(got, result.Index)
+	}
+	if !slices.Equal(got, []int{0, 2, 3}) {
+		t.Fatalf("got indexes %v in order, want 0, 2 and 3", got)
+	}
+}
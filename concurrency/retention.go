@@ -0,0 +1,77 @@
+package typed_goroutine
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Keep only the results of jobs that did not succeed, for pools with too
+// many jobs to hold every result. Wait returns the failed, skipped and
+// panicked jobs, while Stats still counts every job. Results and the
+// results of a handle are not affected.
+func WithRetainFailuresOnly[T any]() Option[T] {
+	return func(p *Pool[T]) error {
+		p.failuresOnly = true
+		return nil
+	}
+}
+
+// Keep only the last n results recorded, so memory stays bounded however
+// many jobs run. Wait returns them ordered by job index, Stats still counts
+// every job and Panics returns every panic. Combined with
+// WithRetainFailuresOnly the last n failures are kept. Results and the
+// results of a handle are not affected.
+func WithResultBuffer[T any](n int) Option[T] {
+	return func(p *Pool[T]) error {
+		if n <= 0 {
+			return fmt.Errorf("%w: result buffer must be positive", ErrInvalidOption)
+		}
+		p.resultBuffer = n
+		return nil
+	}
+}
+
+// Keep a result for Wait as retained WithRetainFailuresOnly and
+// WithResultBuffer, the caller must hold the lock of the pool
+func (p *Pool[T]) keep(result Result[T]) {
+	if p.discardResults || p.abandoned || p.failuresOnly && result.OK {
+		return
+	}
+	if p.resultBuffer == 0 || len(p.results) < p.resultBuffer {
+		if len(p.results) == cap(p.results) {
+			p.grow()
+		}
+		p.results = append(p.results, result)
+		return
+	}
+	// The oldest result is overwritten, Wait sorts them anyway
+	p.results[p.oldest] = result
+	p.oldest = (p.oldest + 1) % p.resultBuffer
+}
+
+// Double the storage of the results, up to the size of the ring
+// WithResultBuffer. The caller must hold the lock of the pool.
+func (p *Pool[T]) grow() {
+	size := max(2*cap(p.results), 16)
+	if p.resultBuffer > 0 {
+		size = min(size, p.resultBuffer)
+	}
+	p.results = slices.Grow(p.results, size-len(p.results))
+}
+
+// Size the storage of the results for the jobs expected WithExpectedJobs.
+// Only results that are kept take up memory, panics and failures are rare
+// enough to grow on demand. The caller must hold the lock of the pool.
+func (p *Pool[T]) reserve() {
+	if p.stream != nil || p.discardResults || p.failuresOnly {
+		return
+	}
+	size := max(p.added, int(p.expectedJobs))
+	if p.resultBuffer > 0 {
+		size = min(size, p.resultBuffer)
+	}
+	// Values of streaming jobs may outnumber the jobs
+	if more := size - len(p.results); more > 0 {
+		p.results = slices.Grow(p.results, more)
+	}
+}
@@ -0,0 +1,64 @@
+package typed_goroutine_test
+
+import (
+	"errors"
+	"testing"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+// Run 100k jobs of which every thousandth fails and every ten thousandth
+// panics, under the given retention
+func retainedRun(t *testing.T, opts ...typed_goroutine.Option[int]) ([]typed_goroutine.Result[int], []typed_goroutine.PanicInfo, typed_goroutine.Stats) {
+	t.Helper()
+	const jobs = 100_000
+	pool := typed_goroutine.NewPool[int](jobs, 8, opts...)
+	errBroken := errors.New("broken")
+	for i := range jobs {
+		if _, err := pool.AddJob(func() (*int, error) {
+			switch {
+			case i%10_000 == 5:
+				panic(i)
+			case i%1000 == 0:
+				return nil, errBroken
+			}
+			return &i, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, panics := pool.Wait()
+	stats := pool.Stats()
+	if stats.Total != jobs || stats.Completed != jobs-110 || stats.Failed != 100 || stats.Panicked != 10 {
+		t.Fatalf("counted %d jobs, %d completed, %d failed and %d panicked, want %d, %d, 100 and 10",
+			stats.Total, stats.Completed, stats.Failed, stats.Panicked, jobs, jobs-110)
+	}
+	if len(panics) != 10 {
+		t.Fatalf("got %d panics, want all 10", len(panics))
+	}
+	return results, panics, stats
+}
+
+func TestRetainFailuresOnly(t *testing.T) {
+	results, _, _ := retainedRun(t, typed_goroutine.WithRetainFailuresOnly[int]())
+	if len(results) != 110 {
+		t.Fatalf("kept %d results, want the 110 that did not succeed", len(results))
+	}
+	for _, result := range results {
+		if result.OK {
+			t.Fatalf("kept the result of job %d, which succeeded", result.Index)
+		}
+	}
+}
+
+func TestResultBuffer(t *testing.T) {
+	results, _, _ := retainedRun(t, typed_goroutine.WithResultBuffer[int](1000))
+	if len(results) != 1000 {
+		t.Fatalf("kept %d results, want 1000", len(results))
+	}
+	for i := 1; i < len(results); i++ {
+		if results[i].Index <= results[i-1].Index {
+			t.Fatalf("results are not ordered by job index")
+		}
+	}
+}
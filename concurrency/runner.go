@@ -0,0 +1,51 @@
+package typed_goroutine
+
+// Unit of work added with AddJobRunner. It may also implement Named,
+// Retryable and Weighted to configure how the pool runs it.
+type Job[T any] interface {
+	Run() (*T, error)
+}
+
+// Job whose name labels its results, see AddNamedJob
+type Named interface {
+	Name() string
+}
+
+// Job retried up to Retries times instead of as set WithRetries, with the
+// backoff and WithRetryIf of the pool
+type Retryable interface {
+	Retries() int
+}
+
+// Job taking up Weight worker slots, see AddJobWeighted
+type Weighted interface {
+	Weight() int64
+}
+
+// Add a job implemented by a type rather than a closure. Its results carry
+// it in Result.Job, so callers can get their type back with a type
+// assertion.
+func (p *Pool[T]) AddJobRunner(job Job[T]) (*JobHandle[T], error) {
+	queued := queuedJob[T]{plain: job.Run, runner: job}
+	if named, ok := job.(Named); ok {
+		queued.name = named.Name()
+	}
+	if weighted, ok := job.(Weighted); ok {
+		queued.weight = weighted.Weight()
+		if queued.weight < 1 || queued.weight > int64(p.MaxWorkers()) {
+			return nil, ErrInvalidWeight
+		}
+	}
+	return p.add(queued, false)
+}
+
+// Number of times an error of the job is retried
+func (p *Pool[T]) retriesOf(job queuedJob[T]) int {
+	if job.policy != nil {
+		return job.policy.Retries
+	}
+	if retryable, ok := job.runner.(Retryable); ok {
+		return retryable.Retries()
+	}
+	return p.retries
+}
@@ -0,0 +1,29 @@
+package typed_goroutine
+
+// Start the jobs in an order permuted by seed rather than in the order they
+// were added, so jobs added next to each other, for example because they
+// hit the same backend, do not all run at once. The same seed starts the
+// same jobs in the same order, results keep the index of their job and
+// Wait still orders them by it. Jobs of a higher priority still go first,
+// and WithFairness the groups still take turns, only in shuffled order
+// within every round, while a group starts its own jobs in order.
+func WithShuffledDispatch[T any](seed int64) Option[T] {
+	return func(p *Pool[T]) error {
+		p.shuffled = true
+		p.seed = seed
+		return nil
+	}
+}
+
+// Position of a job in the order jobs start within their priority and
+// round, a bijection of the index for a given seed so no two jobs tie
+func (p *Pool[T]) orderOf(index int) uint64 {
+	if !p.shuffled {
+		return uint64(index)
+	}
+	// splitmix64
+	z := uint64(p.seed) + uint64(index)*0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
@@ -0,0 +1,54 @@
+package typed_goroutine_test
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+// Indexes of 20 jobs in the order a single worker started them with seed,
+// the last job added having a higher priority
+func shuffledStarts(t *testing.T, seed int64) []int {
+	t.Helper()
+	pool := typed_goroutine.NewPool[int](20, 1, typed_goroutine.WithShuffledDispatch[int](seed))
+	var starts []int
+	job := func(i int) func() (*int, error) {
+		return func() (*int, error) {
+			starts = append(starts, i)
+			return &i, nil
+		}
+	}
+	for i := range 19 {
+		if _, err := pool.AddJob(job(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := pool.AddJobWithPriority(job(19), 1); err != nil {
+		t.Fatal(err)
+	}
+	results, _ := pool.Wait()
+	for i, result := range results {
+		if result.Index != i || *result.Result != i {
+			t.Fatalf("result %d is of job %d with value %d", i, result.Index, *result.Result)
+		}
+	}
+	return starts
+}
+
+func TestShuffledDispatchIsStable(t *testing.T) {
+	starts := shuffledStarts(t, 42)
+	if again := shuffledStarts(t, 42); !reflect.DeepEqual(again, starts) {
+		t.Fatalf("seed 42 started %v, then %v", starts, again)
+	}
+	if starts[0] != 19 {
+		t.Fatalf("started %v, want the job of higher priority first", starts)
+	}
+	if slices.IsSorted(starts[1:]) {
+		t.Fatalf("started %v in the order the jobs were added", starts)
+	}
+	if other := shuffledStarts(t, 7); reflect.DeepEqual(other, starts) {
+		t.Fatalf("seeds 42 and 7 both started %v", starts)
+	}
+}
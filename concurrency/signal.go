@@ -0,0 +1,49 @@
+package typed_goroutine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Run the pool until it is done or one of signals arrives, SIGINT and
+// SIGTERM if none are given, for command line tools. On a signal the pool
+// is stopped like Stop: no further jobs start and running jobs finish, then
+// the results and panics collected so far are returned with an error
+// wrapping ErrInterrupted. A second signal returns right away without
+// waiting for the running jobs.
+func RunUntilSignal[T any](p *Pool[T], signals ...os.Signal) ([]Result[T], []PanicInfo, error) {
+	if len(signals) == 0 {
+		signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	received := make(chan os.Signal, 2)
+	signal.Notify(received, signals...)
+	defer signal.Stop(received)
+	if err := p.Run(); err != nil {
+		return nil, nil, err
+	}
+	var sig os.Signal
+	select {
+	case <-p.doneChan():
+		results, panics := p.Wait()
+		return results, panics, nil
+	case sig = <-received:
+	}
+	force, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-received:
+			cancel()
+		case <-force.Done():
+		}
+	}()
+	_, _, err := p.Stop(force)
+	results, panics, _ := p.snapshot()
+	if err != nil {
+		return results, panics, fmt.Errorf("%w by %v, jobs are still running", ErrInterrupted, sig)
+	}
+	return results, panics, fmt.Errorf("%w by %v", ErrInterrupted, sig)
+}
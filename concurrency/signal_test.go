@@ -0,0 +1,84 @@
+//go:build unix
+
+package typed_goroutine_test
+
+import (
+	"errors"
+	"strings"
+	"syscall"
+	"testing"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+// Run the pool of a blocking job and two more until SIGUSR1, which is sent
+// to the test process once the blocking job started
+func runUntilSignal(t *testing.T, release <-chan struct{}) (*typed_goroutine.Pool[int], chan error, chan []typed_goroutine.Result[int]) {
+	t.Helper()
+	pool := typed_goroutine.NewPool[int](3, 1)
+	started := make(chan struct{})
+	if _, err := pool.AddJob(func() (*int, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if _, err := pool.AddJob(noop); err != nil {
+			t.Fatal(err)
+		}
+	}
+	errs, done := make(chan error, 1), make(chan []typed_goroutine.Result[int], 1)
+	go func() {
+		results, _, err := typed_goroutine.RunUntilSignal(pool, syscall.SIGUSR1)
+		errs <- err
+		done <- results
+	}()
+	<-started
+	sendSignal(t)
+	waitFor(t, func() bool { return pool.State() == typed_goroutine.PoolStopping })
+	return pool, errs, done
+}
+
+func sendSignal(t *testing.T) {
+	t.Helper()
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunUntilSignalStopsGracefully(t *testing.T) {
+	release := make(chan struct{})
+	_, errs, done := runUntilSignal(t, release)
+	// The running job finishes, the others never start
+	close(release)
+	err := <-errs
+	if !errors.Is(err, typed_goroutine.ErrInterrupted) || strings.Contains(err.Error(), "still running") {
+		t.Fatalf("got %v, want ErrInterrupted once every job returned", err)
+	}
+	results := <-done
+	if len(results) != 3 || !results[0].OK {
+		t.Fatalf("got %+v, want the running job to finish", results)
+	}
+	for _, result := range results[1:] {
+		if !result.Skipped || !errors.Is(result.Error, typed_goroutine.ErrPoolStopped) {
+			t.Fatalf("job %d got %v, want it skipped with ErrPoolStopped", result.Index, result.Error)
+		}
+	}
+}
+
+func TestRunUntilSecondSignalReturnsRightAway(t *testing.T) {
+	release := make(chan struct{})
+	pool, errs, _ := runUntilSignal(t, release)
+	sendSignal(t)
+	err := <-errs
+	if !errors.Is(err, typed_goroutine.ErrInterrupted) || !strings.Contains(err.Error(), "still running") {
+		t.Fatalf("got %v, want ErrInterrupted while the job is still running", err)
+	}
+	if state := pool.State(); state == typed_goroutine.PoolDone {
+		t.Fatal("pool is done although its job did not return")
+	}
+	close(release)
+	pool.Wait()
+}
@@ -0,0 +1,82 @@
+package typed_goroutine
+
+import (
+	"context"
+	"fmt"
+)
+
+// Key of the state of a worker in the context of its jobs
+type workerStateKey struct{}
+
+// Stands in for the state of a worker whose init failed
+type workerInitError struct {
+	err error
+}
+
+// Give every worker its own state created by init, for resources that must
+// not be shared between goroutines. Jobs added with AddJobWithState receive
+// the state of the worker running them. teardown, which may be nil, is
+// called with the state when the worker exits, once the pool is done or the
+// worker retired after WithIdleTimeout. A worker whose init fails exits,
+// which reduces the capacity of the pool. The last worker never exits, if
+// its init failed the jobs it runs fail with ErrWorkerInit.
+func WithWorkerState[T, S any](init func(workerID int) (S, error), teardown func(S)) Option[T] {
+	return func(p *Pool[T]) error {
+		if init == nil {
+			return fmt.Errorf("%w: nil worker init", ErrInvalidOption)
+		}
+		p.workerInit = func(id int) (any, error) {
+			return init(id)
+		}
+		if teardown != nil {
+			p.workerTeardown = func(state any) {
+				teardown(state.(S))
+			}
+		}
+		return nil
+	}
+}
+
+// Add a job to p that receives the state of the worker running it, see
+// WithWorkerState. The job fails with ErrNoWorkerState if the pool has no
+// worker state of type S.
+func AddJobWithState[T, S any](p *Pool[T], job func(state S) (*T, error)) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{fn: func(ctx context.Context) (*T, error) {
+		switch state := ctx.Value(workerStateKey{}).(type) {
+		case S:
+			return job(state)
+		case workerInitError:
+			return nil, fmt.Errorf("%w: %w", ErrWorkerInit, state.err)
+		default:
+			return nil, ErrNoWorkerState
+		}
+	}}, false)
+}
+
+// Create the state of a worker and add it to ctx, returns a function that
+// tears the state down. Reports false if the worker should exit instead
+// because its init failed.
+func (p *Pool[T]) initWorker(ctx context.Context, id int) (context.Context, func(), bool) {
+	state, err := p.callInit(id)
+	if err != nil {
+		if p.retire() {
+			return ctx, nil, false
+		}
+		return context.WithValue(ctx, workerStateKey{}, workerInitError{err}), func() {}, true
+	}
+	teardown := func() {}
+	if p.workerTeardown != nil {
+		teardown = func() { p.workerTeardown(state) }
+	}
+	return context.WithValue(ctx, workerStateKey{}, state), teardown, true
+}
+
+// Call the worker init, a panic counts as a failed init
+func (p *Pool[T]) callInit(id int) (state any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("worker init panicked: %v", r)
+		}
+	}()
+	return p.workerInit(id)
+}
@@ -0,0 +1,121 @@
+package typed_goroutine
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Snapshot of the state of a pool
+type Stats struct {
+	// Jobs added to the pool
+	Total int
+	// Jobs waiting to be started
+	Queued int
+	// Jobs currently running
+	Running int
+	// Current limit on the jobs running at the same time, which changes
+	// WithAdaptiveConcurrency
+	MaxWorkers int
+	// Jobs that returned without an error
+	Completed int
+	// Jobs that returned an error
+	Failed int
+	// Jobs that panicked
+	Panicked int
+	// Jobs that were never started
+	Skipped int
+	// Time since the pool started, stops growing once it is done
+	Elapsed time.Duration
+	// Why the pool stopped starting jobs early, ErrPoolStopped,
+	// ErrFailFastTriggered, ErrTooManyErrors or ErrCircuitOpen, nil if it
+	// did not
+	Halted error
+	// State of the circuit breaker, closed without WithCircuitBreaker
+	Circuit CircuitState
+	// Keyed jobs found and not found in the cache set WithCache
+	CacheHits   int
+	CacheMisses int
+	// Values emitted by jobs added with AddStreamingJob, which are results
+	// on top of the one every job has
+	Emitted int
+}
+
+// Counters behind Stats, kept apart from the lock so reading them does not
+// contend with the workers
+type counters struct {
+	added       atomic.Int64
+	running     atomic.Int64
+	completed   atomic.Int64
+	failed      atomic.Int64
+	panicked    atomic.Int64
+	skipped     atomic.Int64
+	cacheHits   atomic.Int64
+	cacheMisses atomic.Int64
+	emitted     atomic.Int64
+	// Dispatches the scheduler took from the queue that wait for workers
+	waiting atomic.Int64
+	// Unix nanoseconds at which the pool started and was done
+	startedAt atomic.Int64
+	doneAt    atomic.Int64
+}
+
+func (c *counters) reset() {
+	c.added.Store(0)
+	c.running.Store(0)
+	c.completed.Store(0)
+	c.failed.Store(0)
+	c.panicked.Store(0)
+	c.skipped.Store(0)
+	c.cacheHits.Store(0)
+	c.cacheMisses.Store(0)
+	c.emitted.Store(0)
+	c.waiting.Store(0)
+	c.startedAt.Store(0)
+	c.doneAt.Store(0)
+}
+
+// Snapshot of the counters of the pool, safe to call at any time. While
+// the pool runs the counters are read one by one and may be slightly out of
+// step, once it is done Completed, Failed, Panicked and Skipped add up to
+// Total.
+func (p *Pool[T]) Stats() Stats {
+	c := &p.counters
+	stats := Stats{
+		Total:       int(c.added.Load()),
+		Running:     int(c.running.Load()),
+		MaxWorkers:  int(p.workerLimit.limit()),
+		Completed:   int(c.completed.Load()),
+		Failed:      int(c.failed.Load()),
+		Panicked:    int(c.panicked.Load()),
+		Skipped:     int(c.skipped.Load()),
+		CacheHits:   int(c.cacheHits.Load()),
+		CacheMisses: int(c.cacheMisses.Load()),
+		Emitted:     int(c.emitted.Load()),
+	}
+	stats.Queued = stats.Total - stats.Running - stats.Completed - stats.Failed - stats.Panicked - stats.Skipped
+	if stats.Queued < 0 {
+		stats.Queued = 0
+	}
+	switch {
+	case p.stopped.Load():
+		stats.Halted = ErrPoolStopped
+	case p.failed.Load():
+		stats.Halted = ErrFailFastTriggered
+	case p.tooManyErrors.Load():
+		stats.Halted = ErrTooManyErrors
+	}
+	if p.breaker != nil {
+		stats.Circuit = p.breaker.current()
+		if stats.Halted == nil && stats.Circuit != CircuitClosed {
+			stats.Halted = ErrCircuitOpen
+		}
+	}
+	if started := c.startedAt.Load(); started != 0 {
+		end := c.doneAt.Load()
+		if end == 0 {
+			end = p.clock.Now().UnixNano()
+		}
+		stats.Elapsed = time.Duration(end - started)
+	}
+	return stats
+}
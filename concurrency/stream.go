@@ -0,0 +1,46 @@
+package typed_goroutine
+
+import "context"
+
+// Add a job that produces any number of values by calling emit, each of
+// which becomes a result of its own with the index of the job and Part
+// counting up from 0. Once the job returns it gets a final result with
+// Part set to the number of values it emitted, which carries its error or,
+// if it succeeded, no value and is left out by Results.Successes. Only the
+// final result completes the handle and counts as a job in Stats, the
+// values are counted as Emitted. Values go wherever results go, to Wait,
+// Results or OrderedResults, which delivers them in the order they were
+// emitted. A value emitted after the job returned is ignored, a retried
+// job emits its values again.
+func (p *Pool[T]) AddStreamingJob(job func(emit func(*T)) error) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{fn: func(ctx context.Context) (*T, error) {
+		scope, _ := ctx.Value(jobInfoKey{}).(*jobScope)
+		return nil, job(func(value *T) {
+			p.emitPart(scope, value)
+		})
+	}, streaming: true}, false)
+}
+
+// Record a value emitted by a running streaming job
+func (p *Pool[T]) emitPart(scope *jobScope, value *T) {
+	scope.mu.Lock()
+	if scope.done {
+		scope.mu.Unlock()
+		return
+	}
+	part := scope.parts
+	scope.parts++
+	scope.mu.Unlock()
+	result := Result[T]{Result: value, OK: true, Index: scope.info.Index, Name: scope.info.Name, Part: part, Meta: Meta{Worker: scope.info.Worker}, partial: true}
+	if value != nil {
+		result.Value = *value
+	}
+	p.counters.emitted.Add(1)
+	if p.stream != nil {
+		p.send(result)
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keep(result)
+}
@@ -0,0 +1,88 @@
+package typed_goroutine_test
+
+import (
+	"errors"
+	"testing"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+func TestStreamingJobEmitsInOrder(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](2, 2)
+	errParse := errors.New("parse failed")
+	if _, err := pool.AddStreamingJob(func(emit func(*int)) error {
+		for i := 1; i <= 3; i++ {
+			emit(&i)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.AddStreamingJob(func(emit func(*int)) error {
+		value := 10
+		emit(&value)
+		return errParse
+	}); err != nil {
+		t.Fatal(err)
+	}
+	results, _ := pool.Wait()
+	type part struct {
+		index, part, value int
+		ok                 bool
+	}
+	want := []part{{0, 0, 1, true}, {0, 1, 2, true}, {0, 2, 3, true}, {0, 3, 0, true}, {1, 0, 10, true}, {1, 1, 0, false}}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(results), len(want))
+	}
+	for i, result := range results {
+		if got := (part{result.Index, result.Part, result.Value, result.OK}); got != want[i] {
+			t.Errorf("result %d: got %+v, want %+v", i, got, want[i])
+		}
+	}
+	if !errors.Is(results[5].Error, errParse) {
+		t.Errorf("final result of job 1 got %v, want its error", results[5].Error)
+	}
+	if stats := pool.Stats(); stats.Total != 2 || stats.Emitted != 4 {
+		t.Errorf("got %d jobs and %d emitted, want 2 and 4", stats.Total, stats.Emitted)
+	}
+}
+
+// An emit kept past the end of its job delivers nothing, also once the
+// channel of Results is closed
+func TestEmitAfterJobReturnedIsIgnored(t *testing.T) {
+	for _, streamed := range []bool{false, true} {
+		pool := typed_goroutine.NewPool[int](1, 1)
+		var late func(*int)
+		if _, err := pool.AddStreamingJob(func(emit func(*int)) error {
+			late = emit
+			value := 1
+			emit(&value)
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+		var results []typed_goroutine.Result[int]
+		if streamed {
+			stream := pool.Results()
+			if err := pool.Run(); err != nil {
+				t.Fatal(err)
+			}
+			for result := range stream {
+				results = append(results, result)
+			}
+		} else {
+			results, _ = pool.Wait()
+		}
+		value := 2
+		late(&value)
+		if !streamed {
+			results, _ = pool.Wait()
+		}
+		if len(results) != 2 || results[0].Value != 1 || results[1].Part != 1 {
+			t.Fatalf("streamed %v: got %+v, want the emitted value and the final result", streamed, results)
+		}
+		if emitted := pool.Stats().Emitted; emitted != 1 {
+			t.Fatalf("streamed %v: got %d emitted, want 1", streamed, emitted)
+		}
+	}
+}
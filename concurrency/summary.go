@@ -0,0 +1,203 @@
+package typed_goroutine
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Number of the slowest jobs a Summary lists
+const summarySlowest = 5
+
+// Job that failed, panicked or was skipped, see Summary
+type JobFailure struct {
+	Index int    `json:"index"`
+	Name  string `json:"name"`
+	// Message of the error of the job without the *JobError around it
+	Error    string `json:"error"`
+	Panicked bool   `json:"panicked,omitempty"`
+	Skipped  bool   `json:"skipped,omitempty"`
+}
+
+// Warning of a job, see Summary
+type JobWarning struct {
+	Index   int    `json:"index"`
+	Name    string `json:"name"`
+	Warning string `json:"warning"`
+}
+
+// How long a job ran, see Summary
+type JobTiming struct {
+	Index    int           `json:"index"`
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration_ns"`
+}
+
+// Summarize the results and panics returned by Wait, for example to end a
+// command line tool with:
+//
+//	summary := Summarize(pool.Wait())
+//	fmt.Print(summary)
+//	os.Exit(summary.ExitCode())
+//
+// A panic is counted once whether it is among the results, the panics or
+// both, so the panics of a pool created WithDiscardResults are summarized
+// too. Elapsed spans from the first job starting to the last one ending,
+// and is zero if the results carry no timestamps.
+func Summarize[T any](results []Result[T], panics []PanicInfo) Summary[T] {
+	summary := Summary[T]{Jobs: slices.Clone(results)}
+	panicked := make(map[int]bool)
+	for _, result := range results {
+		if result.partial {
+			continue
+		}
+		summary.Total++
+		err := result.err()
+		switch {
+		case err == nil:
+			summary.Completed++
+		case result.Skipped:
+			summary.Skipped++
+		case isPanicError(err):
+			panicked[result.Index] = true
+			summary.Panicked++
+		default:
+			summary.Failed++
+		}
+	}
+	for _, info := range panics {
+		if panicked[info.Index] {
+			continue
+		}
+		summary.Total++
+		summary.Panicked++
+		panicErr := &PanicError{Value: info.Value, Stack: info.Stack}
+		summary.Jobs = append(summary.Jobs, Result[T]{
+			Error: &JobError{Index: info.Index, Name: info.Name, Attempt: info.Attempts, Err: panicErr},
+			Index: info.Index,
+			Name:  info.Name,
+			Panic: &info,
+			Meta:  Meta{Worker: -1, Attempts: info.Attempts, StartedAt: info.StartedAt, Duration: info.Duration},
+		})
+	}
+	var first, last time.Time
+	for _, result := range summary.Jobs {
+		if result.StartedAt.IsZero() {
+			continue
+		}
+		if first.IsZero() || result.StartedAt.Before(first) {
+			first = result.StartedAt
+		}
+		if end := result.StartedAt.Add(result.Duration); end.After(last) {
+			last = end
+		}
+	}
+	summary.Elapsed = last.Sub(first)
+	sortResults(summary.Jobs)
+	summary.describe()
+	return summary
+}
+
+// List the failures and the slowest of the jobs
+func (s *Summary[T]) describe() {
+	s.Failures, s.Warnings, s.Slowest = nil, nil, nil
+	for _, result := range s.Jobs {
+		if result.partial {
+			continue
+		}
+		for _, warning := range result.Warnings {
+			s.Warnings = append(s.Warnings, JobWarning{Index: result.Index, Name: result.Name, Warning: warning.Error()})
+		}
+		if result.Duration > 0 {
+			s.Slowest = append(s.Slowest, JobTiming{Index: result.Index, Name: result.Name, Duration: result.Duration})
+		}
+		err := result.err()
+		if err == nil {
+			continue
+		}
+		var jobErr *JobError
+		if errors.As(err, &jobErr) {
+			err = jobErr.Err
+		}
+		s.Failures = append(s.Failures, JobFailure{
+			Index:    result.Index,
+			Name:     result.Name,
+			Error:    err.Error(),
+			Panicked: !result.Skipped && isPanicError(err),
+			Skipped:  result.Skipped,
+		})
+	}
+	sort.SliceStable(s.Slowest, func(i, j int) bool {
+		return s.Slowest[i].Duration > s.Slowest[j].Duration
+	})
+	if len(s.Slowest) > summarySlowest {
+		s.Slowest = s.Slowest[:summarySlowest]
+	}
+}
+
+// Report over several lines, for example:
+//
+//	crawl: 4 jobs: 1 completed, 1 failed, 1 panicked, 1 skipped in 1.5s
+//	failures:
+//	  job 1 (fetch): connection refused
+//	  job 2: job panicked: oops
+//	  job 3 skipped: job skipped after a job failed
+//	warnings:
+//	  job 0: skipped row 7
+//	slowest:
+//	  job 1 (fetch): 1.2s
+//	  job 0: 300ms
+//
+// The name of the pool is left out if it has none, and so are the elapsed
+// time and the sections without any jobs.
+func (s Summary[T]) String() string {
+	var b strings.Builder
+	if s.Name != "" {
+		fmt.Fprintf(&b, "%s: ", s.Name)
+	}
+	fmt.Fprintf(&b, "%d jobs: %d completed, %d failed, %d panicked, %d skipped",
+		s.Total, s.Completed, s.Failed, s.Panicked, s.Skipped)
+	if s.Elapsed > 0 {
+		fmt.Fprintf(&b, " in %v", s.Elapsed)
+	}
+	b.WriteByte('\n')
+	if len(s.Failures) > 0 {
+		b.WriteString("failures:\n")
+		for _, failure := range s.Failures {
+			fmt.Fprintf(&b, "  %s", jobLabel(failure.Index, failure.Name))
+			if failure.Skipped {
+				b.WriteString(" skipped")
+			}
+			fmt.Fprintf(&b, ": %s\n", failure.Error)
+		}
+	}
+	if len(s.Warnings) > 0 {
+		b.WriteString("warnings:\n")
+		for _, warning := range s.Warnings {
+			fmt.Fprintf(&b, "  %s: %s\n", jobLabel(warning.Index, warning.Name), warning.Warning)
+		}
+	}
+	if len(s.Slowest) > 0 {
+		b.WriteString("slowest:\n")
+		for _, timing := range s.Slowest {
+			fmt.Fprintf(&b, "  %s: %v\n", jobLabel(timing.Index, timing.Name), timing.Duration)
+		}
+	}
+	return b.String()
+}
+
+// Exit code for a command line tool that ran the jobs: 2 if a job panicked,
+// 1 if a job failed or was skipped, 0 if every job completed, with warnings
+// or not
+func (s Summary[T]) ExitCode() int {
+	switch {
+	case s.Panicked > 0:
+		return 2
+	case s.Failed > 0 || s.Skipped > 0:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,58 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+	"github.com/demy076/typed_goroutines/concurrency/typedpooltest"
+)
+
+func TestSummaryString(t *testing.T) {
+	clock := typedpooltest.NewClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	pool := typed_goroutine.NewPool[int](4, 1,
+		typed_goroutine.WithName[int]("crawl"),
+		typed_goroutine.WithClock[int](clock),
+	)
+	// A single worker runs the jobs one after the other, each taking as
+	// long as it moves the clock
+	if _, err := pool.AddJobCtx(func(ctx context.Context) (*int, error) {
+		typed_goroutine.Warn(ctx, errors.New("skipped row 7"))
+		clock.Advance(300 * time.Millisecond)
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.AddJob(func() (*int, error) {
+		clock.Advance(100 * time.Millisecond)
+		panic("oops")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.AddNamedJob("fetch", func() (*int, error) {
+		clock.Advance(1200 * time.Millisecond)
+		return nil, errors.New("connection refused")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// Skipped since the job it depends on failed
+	if _, err := pool.AddDependentJob(noop, 2); err != nil {
+		t.Fatal(err)
+	}
+	summary := typed_goroutine.Summarize(pool.Wait())
+	summary.Name = pool.Name()
+	golden(t, "summary.txt", []byte(summary.String()))
+	if code := summary.ExitCode(); code != 2 {
+		t.Fatalf("got exit code %d, want 2 for a panic", code)
+	}
+}
+
+func TestSummarizeNothing(t *testing.T) {
+	summary := typed_goroutine.Summarize[int](nil, nil)
+	golden(t, "empty_summary.txt", []byte(summary.String()))
+	if code := summary.ExitCode(); code != 0 {
+		t.Fatalf("got exit code %d, want 0", code)
+	}
+}
@@ -0,0 +1,60 @@
+package typed_goroutine
+
+import "fmt"
+
+// Add a job to pool running fn with the value of the job of h once it
+// succeeded, to chain jobs of different pools. Until then the job waits
+// without taking a worker and keeps pool from finishing, even if the pool
+// of h never runs. If the job of h fails, panics or is skipped, the job is
+// skipped without running with an error wrapping ErrDependencyFailed and
+// the error of h. Stopping, draining or cancelling pool, or its deadline,
+// skips the job as well without waiting for h any longer. If pool no
+// longer accepts jobs the returned handle is done right away with the
+// error of adding the job.
+func Then[T, U any](h *JobHandle[T], pool *Pool[U], fn func(T) (*U, error)) *JobHandle[U] {
+	next, err := pool.add(queuedJob[U]{held: true, plain: func() (*U, error) {
+		return fn(h.result.Value)
+	}}, true)
+	if err != nil {
+		next = newJobHandle(pool, -1)
+		next.complete(Result[U]{Error: err, Index: -1, Skipped: true, Meta: Meta{Worker: -1}}, true)
+		return next
+	}
+	go func() {
+		select {
+		case <-h.Done():
+		case <-next.Done():
+			// Skipped once pool stopped scheduling, or cancelled
+			return
+		}
+		if h.result.OK {
+			pool.releaseHeld(next, nil)
+			return
+		}
+		pool.releaseHeld(next, fmt.Errorf("%w: %w", ErrDependencyFailed, h.result.err()))
+	}()
+	return next
+}
+
+// Queue a job held in the delay queue, or skip it with err. Does nothing if
+// the job was cancelled or skipped in the meantime.
+func (p *Pool[T]) releaseHeld(h *JobHandle[T], err error) {
+	p.mu.Lock()
+	if !h.delayed {
+		p.mu.Unlock()
+		return
+	}
+	job := p.delays.remove(h.pos)
+	h.delayed = false
+	p.blockedOn--
+	if err == nil {
+		job.held = false
+		p.push(job)
+	}
+	p.queued.Broadcast()
+	p.wakeDelays()
+	p.mu.Unlock()
+	if err != nil {
+		p.skip(job, err)
+	}
+}
@@ -0,0 +1,81 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"testing"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+func TestThenRunsWithValueOfUpstreamJob(t *testing.T) {
+	upstream := typed_goroutine.NewPool[int](1, 1)
+	downstream := typed_goroutine.NewPool[string](1, 1)
+	h, err := upstream.AddJobV(func() (int, error) { return 2, nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := typed_goroutine.Then(h, downstream, func(v int) (*string, error) {
+		s := strconv.Itoa(v * 2)
+		return &s, nil
+	})
+	if err := downstream.Run(); err != nil {
+		t.Fatal(err)
+	}
+	upstream.Wait()
+	results, _ := downstream.Wait()
+	if len(results) != 1 || !results[0].OK || *results[0].Result != "4" {
+		t.Fatalf("got %+v, want the downstream job to return 4", results)
+	}
+	if result, _ := next.Result(); result.Index != results[0].Index {
+		t.Fatalf("handle reports job %d, want %d", result.Index, results[0].Index)
+	}
+}
+
+// The pool of the upstream job never runs, so only the downstream pool
+// ending its run releases the job
+func TestThenSkippedOnceDownstreamPoolEnds(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		end  func(pool *typed_goroutine.Pool[int], cancel context.CancelFunc)
+		want error
+	}{
+		{"stop", func(pool *typed_goroutine.Pool[int], _ context.CancelFunc) {
+			pool.Stop(context.Background())
+		}, typed_goroutine.ErrPoolStopped},
+		{"drain", func(pool *typed_goroutine.Pool[int], _ context.CancelFunc) {
+			pool.Drain(context.Background())
+		}, typed_goroutine.ErrPoolStopped},
+		{"cancel", func(_ *typed_goroutine.Pool[int], cancel context.CancelFunc) {
+			cancel()
+		}, context.Canceled},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			before := runtime.NumGoroutine()
+			upstream := typed_goroutine.NewPool[int](1, 1)
+			downstream := typed_goroutine.NewPool[int](1, 1)
+			h, err := upstream.AddJob(func() (*int, error) { return nil, nil })
+			if err != nil {
+				t.Fatal(err)
+			}
+			next := typed_goroutine.Then(h, downstream, func(int) (*int, error) {
+				t.Error("job ran without its dependency")
+				return nil, nil
+			})
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if err := downstream.RunWithContext(ctx); err != nil {
+				t.Fatal(err)
+			}
+			tc.end(downstream, cancel)
+			results, _ := downstream.Wait()
+			if len(results) != 1 || !results[0].Skipped || !errors.Is(results[0].Error, tc.want) {
+				t.Fatalf("got %+v, want the job skipped with %v", results, tc.want)
+			}
+			<-next.Done()
+			waitFor(t, func() bool { return runtime.NumGoroutine() <= before })
+		})
+	}
+}
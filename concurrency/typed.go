@@ -1,97 +1,2185 @@
+// Package typed_goroutine provides a generic worker pool that runs jobs
+// concurrently and collects their typed results.
+//
+// A pool is created with a capacity hint and a maximum number of workers,
+// filled with jobs and then waited on:
+//
+//	pool := typed_goroutine.NewPool[int](2, 2)
+//	pool.AddJob(func() (*int, error) {
+//		n := 1
+//		return &n, nil
+//	})
+//	pool.AddJob(func() (*int, error) {
+//		return nil, errors.New("failed")
+//	})
+//	results, panics := pool.Wait()
 package typed_goroutine
 
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+	"runtime/pprof"
+	"slices"
+	"sort"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
-	"golang.org/x/sync/semaphore"
+	"golang.org/x/time/rate"
 )
 
 type Result[T any] struct {
+	// Value returned by a job added with a pointer, nil for jobs returning
+	// a value
 	Result *T
-	Error  error
+	// Value returned by the job if it succeeded, for both kinds of jobs
+	Value T
+	// Whether the job succeeded, Value holds its result
+	OK bool
+	// Whether the job never started, Error tells why
+	Skipped bool
+	// Error of the job as a *JobError, use errors.Is to match the cause
+	Error error
+	// Position of the job in the order it was added to the pool
+	Index int
+	// Name of the job, its index unless it was added with a name
+	Name string
+	// Iteration of a job added with AddJobRepeated, 0 for any other job
+	Iteration int
+	// Position of a value emitted by a job added with AddStreamingJob, the
+	// number of values for its final result, 0 for any other job
+	Part int
+	// Job added with AddJobRunner, nil for any other job
+	Job Job[T]
+	// Name of the Policy the job was added with, empty if it has none
+	Policy string
+	// Panic of the job, nil if it did not panic or panics are converted to
+	// errors
+	Panic *PanicInfo
+	// How the job ran, its fields can be used as fields of the result
+	Meta
+	// Job of a result that did not succeed WithRetainJobs
+	retained *queuedJob[T]
+	// Value emitted by a streaming job, and the final result of one that
+	// succeeded, which holds no value
+	partial, streamed bool
+}
+
+// How a job ran: where, when, for how long, how often and what it warned
+// about, see SkewReport
+type Meta struct {
+	// Problems the job reported with Warn or from AddJobW, which did not
+	// make it fail
+	Warnings []error
+	// ID of the worker that ran the job from 0 up to the workers started,
+	// -1 if the job did not run
+	Worker int
+	// Number of times the job was executed
+	Attempts int
+	// Whether the job failed with an error that would have been retried
+	// after using up its retries, false if WithRetryIf did not classify the
+	// error as retryable or no retries are configured
+	RetriesExhausted bool
+	// When the job started, zero if it was skipped
+	StartedAt time.Time
+	// Time spent running the job including retries
+	Duration time.Duration
+	// Whether the job ran for at least the threshold set
+	// WithSlowJobThreshold
+	Slow bool
+	// Whether a hedge of a job added with AddHedgedJob finished first
+	Hedged bool
+	// Time the job waited for a worker, counted from when it was added or
+	// the pool started, whichever came last
+	QueueWait time.Duration
+}
+
+// Value of a successful job, or the error of a failed one. A recorded
+// panic is returned as a *PanicError.
+func (r Result[T]) Get() (T, error) {
+	var zero T
+	if r.Panic != nil {
+		return zero, &PanicError{Value: r.Panic.Value, Stack: r.Panic.Stack}
+	}
+	if r.Error != nil {
+		return zero, r.Error
+	}
+	return r.Value, nil
+}
+
+// Value of a successful job, panics with the error of a failed one
+func (r Result[T]) MustGet() T {
+	value, err := r.Get()
+	if err != nil {
+		panic(err)
+	}
+	return value
+}
+
+// A panic recovered from a job
+type PanicInfo struct {
+	// Value passed to panic
+	Value interface{}
+	// Position of the job in the order it was added to the pool
+	Index int
+	// Name of the job, its index unless it was added with a name
+	Name string
+	// Stack trace of the goroutine captured while recovering
+	Stack []byte
+	// When the job started
+	StartedAt time.Time
+	// Time spent running the job until it panicked
+	Duration time.Duration
+	// Number of times the job was executed, including retries
+	Attempts int
+	// Value passed to panic by the panic handler while handling this panic,
+	// nil unless the handler panicked
+	HandlerPanic interface{}
+}
+
+// Error of a single job of a pool, every error reported for a job is
+// wrapped in one
+type JobError struct {
+	// Position of the job in the order it was added to the pool
+	Index int
+	Name  string
+	// Number of times the job was executed, 0 if it never started
+	Attempt int
+	Err     error
+}
+
+func (e *JobError) Error() string {
+	return fmt.Sprintf("%s: %v", jobLabel(e.Index, e.Name), e.Err)
+}
+
+// Describe a job by its index and its name if it has one
+func jobLabel(index int, name string) string {
+	if name != "" && name != strconv.Itoa(index) {
+		return fmt.Sprintf("job %d (%s)", index, name)
+	}
+	return fmt.Sprintf("job %d", index)
+}
+
+func (e *JobError) Unwrap() error {
+	return e.Err
+}
+
+// Value Wait panics with for a pool created WithPropagatePanics
+type PropagatedPanic struct {
+	// First panic recorded by the pool
+	Info PanicInfo
+}
+
+// Describe the panic together with the stack of the job that raised it
+func (e *PropagatedPanic) Error() string {
+	return fmt.Sprintf("%s panicked: %v\n\n%s", jobLabel(e.Info.Index, e.Info.Name), e.Info.Value, e.Info.Stack)
+}
+
+// Unwrap the panic value if the job panicked with an error
+func (e *PropagatedPanic) Unwrap() error {
+	err, _ := e.Info.Value.(error)
+	return err
+}
+
+// Error reported for a panicking job when panics are converted to errors
+type PanicError struct {
+	// Value passed to panic
+	Value interface{}
+	// Stack trace of the goroutine captured while recovering
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("job panicked: %v", e.Value)
+}
+
+// Unwrap the panic value if the job panicked with an error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
 }
 
 type Pool[T any] struct {
-	Jobs            []func() (*T, error)
-	MaxWorkers      uint
-	running         bool
-	results         []Result[T]
-	results_chan    chan Result[T]
-	panics          []interface{}
-	panicC          chan interface{}
-	workerSemaphore *semaphore.Weighted
-	workerGroup     sync.WaitGroup
+	name         string
+	ctx          context.Context
+	baseCtx      context.Context
+	cancel       context.CancelCauseFunc
+	maxWorkers   uint
+	expectedJobs uint
+	// Guards the queue, the running state and the collected results
+	mu      sync.Mutex
+	queued  *sync.Cond
+	resumed *sync.Cond
+	// Signalled when the scheduler takes a job off the queue
+	dequeued *sync.Cond
+	// Jobs are fed from a channel, so the queue stays open after start
+	feeding bool
+	paused  bool
+	// Cancels the context jobs are scheduled with, but not the one they
+	// run with
+	stopScheduling context.CancelFunc
+	stopped        atomic.Bool
+	stopSkipped    atomic.Int64
+	queue          jobQueue[T]
+	queueSize      int
+	added          int
+	// Index of the next job, and the indexes of the jobs RetryFailed
+	// created the pool with in order, which the jobs added later follow
+	nextIndex int
+	retried   []int
+	// Jobs with dependencies, which are queued once those succeeded
+	waiting    []waitingJob[T]
+	dependents map[int][]*waitingJob[T]
+	// Waiting and delayed jobs that were neither queued nor skipped yet,
+	// and running jobs that may yield
+	blockedOn int
+	// Jobs added with a delay, released into the queue by their own
+	// goroutine which is woken up through delayWake
+	delays     delayQueue[T]
+	delayWake  chan struct{}
+	delayGroup sync.WaitGroup
+	// Set on start before any job runs, so completing jobs check for
+	// dependents only when there are any
+	hasDependencies bool
+	// Handles of the jobs of every group in the order they were added
+	groups map[string][]*JobHandle[T]
+	// Next round of every group and the round of the job started last
+	// WithFairness
+	fair   bool
+	rounds map[string]int
+	round  int
+	// Seed of the order jobs start in WithShuffledDispatch
+	shuffled bool
+	seed     int64
+	// Checkpoints between yields WithPreemptiveCheckpoints, and the jobs
+	// that yielded so far, which gives them their order once queued again
+	preemptEvery uint
+	yields       uint64
+	// Workers of the parent of a pool created with Child, shared
+	// WithParentLimit
+	parentLimit Limiter
+	// Policies of the jobs of a group WithGroupPolicy
+	policies map[string]*Policy
+	// Jobs following the unfinished job of their key, see AddJobKeyed
+	keyed     map[string][]queuedJob[T]
+	dynamic   bool
+	closed    bool
+	running   bool
+	startedAt time.Time
+	results   []Result[T]
+	panics    []PanicInfo
+	// Whether results and panics were sorted by a Wait
+	sorted     bool
+	firstPanic *PanicInfo
+	finished   int
+	// Closed once another job finished, nil unless WaitN waits for it
+	recorded  chan struct{}
+	counters  counters
+	stream    chan Result[T]
+	errorFeed *errorFeed
+	done      chan struct{}
+	// Closed once every worker exited, which after Drain gave up on the
+	// running jobs is later than done
+	exited      chan struct{}
+	abandoned   bool
+	workerGroup sync.WaitGroup
+	// Weight of the jobs being started or running
+	workerLimit *workerLimit
+	// Started worker goroutines and what they need, so more can be started
+	// while running
+	workers       uint
+	nextWorkerID  int
+	work          chan dispatch[T]
+	runCtx        context.Context
+	schedulingCtx context.Context
+	failFast      bool
+	// Set while the scheduler waits for a worker to take a job
+	// WithIdleTimeout, no worker retires in the meantime
+	handing bool
+	// Why the run ended early, see CancelCause
+	endCause error
+	// Error returned by Drain once it gave up on running jobs
+	drainErr   error
+	firstError bool
+	firstErr   error
+	// Results kept WithRetainFailuresOnly, the size of the ring of results
+	// WithResultBuffer and the position of the oldest once it is full
+	failuresOnly bool
+	resultBuffer int
+	oldest       int
+	// Called once a job failed or panicked, set by Join2 and Join3
+	onFailure       func(err error)
+	serial          bool
+	batchSize       int
+	acquireTimeout  time.Duration
+	sharedLimit     Limiter
+	idleTimeout     time.Duration
+	workerInit      func(id int) (any, error)
+	workerTeardown  func(state any)
+	discardResults  bool
+	retainJobs      bool
+	retainPanics    bool
+	failed          atomic.Bool
+	maxErrors       uint
+	breaker         *breaker
+	adaptive        *adaptive
+	adaptiveIf      func(err error) bool
+	errorCount      atomic.Int64
+	tooManyErrors   atomic.Bool
+	retries         int
+	retryIf         func(err error) bool
+	panicRetries    int
+	backoff         func(attempt int) time.Duration
+	panicsAsErrors  bool
+	propagatePanics bool
+	panicHandler    func(PanicInfo)
+	limiter         *rate.Limiter
+	jobTimeout      time.Duration
+	// Deadline of a run, the earlier of deadline and timeout after start
+	deadline        time.Time
+	timeout         time.Duration
+	detachOnTimeout bool
+	onProgress      func(Progress)
+	onJobStart      func(index int)
+	onJobEnd        func(index int, result Result[T])
+	onError         func(index int, name string, err error)
+	collector       Collector
+	middleware      []Middleware[T]
+	cache           Cache[T]
+	cachedErrorsTTL time.Duration
+	pprofLabels     bool
+	slowThreshold   time.Duration
+	onSlow          func(index int, name string, elapsed time.Duration)
+	// Heartbeats of the running jobs by index, the jobs run by Execute
+	// are numbered below -1 in the order they started
+	beatsMu      sync.Mutex
+	beats        map[int]*jobScope
+	adHoc        int
+	stallTimeout time.Duration
+	onStall      func(index int, name string, last time.Time, msg string)
+	jobContext   func(ctx context.Context, index int, name string) (context.Context, func(Result[T]))
+	logger       *slog.Logger
+	clock        Clock
+	jobLogLevel  slog.Level
+	// Wakes up the progress goroutine, nil unless it runs
+	progressed chan struct{}
+	// Options the pool was created with, which RetryFailed applies again
+	opts []Option[T]
 }
 
 // Create easy to compare errors for this pool
 var (
-	ErrAlreadyRunning     = errors.New("pool is running")
-	ErrAcquiringSemaphore = errors.New("failed to acquire semaphore")
+	ErrAlreadyRunning       = errors.New("pool is running")
+	ErrAcquiringSemaphore   = errors.New("failed to acquire semaphore")
+	ErrSkipped              = errors.New("job skipped")
+	ErrFailFastTriggered    = fmt.Errorf("%w after a job failed", ErrSkipped)
+	ErrWaitTimeout          = errors.New("timed out waiting for pool")
+	ErrPoolClosed           = errors.New("pool is closed")
+	ErrNotRunning           = errors.New("pool is not running")
+	ErrPoolFinished         = errors.New("pool is finished")
+	ErrNotPaused            = errors.New("pool is not paused")
+	ErrPoolStopped          = errors.New("pool was stopped")
+	ErrPoolDrained          = errors.New("pool was drained")
+	ErrInterrupted          = errors.New("pool interrupted")
+	ErrJobCancelled         = errors.New("job was cancelled")
+	ErrJobTimeout           = errors.New("job timed out")
+	ErrPoolDeadlineExceeded = errors.New("pool deadline exceeded")
+	ErrUnknownGroup         = errors.New("unknown job group")
+	ErrTooManyErrors        = errors.New("job skipped after too many errors")
+	ErrQueueFull            = errors.New("queue is full")
+	ErrNotEnoughJobs        = errors.New("not enough jobs")
+	ErrNoSuccess            = errors.New("no job succeeded")
+	ErrCircuitOpen          = errors.New("job skipped while the circuit breaker is open")
+	ErrWorkerInit           = errors.New("worker init failed")
+	ErrNoWorkerState        = errors.New("pool has no worker state of this type")
+	ErrDependencyFailed     = errors.New("dependency of job failed")
+	ErrDependencyCycle      = errors.New("dependency cycle")
+	ErrUnknownDependency    = errors.New("unknown dependency")
+	ErrInvalidOption        = errors.New("invalid pool option")
+	ErrInvalidWeight        = errors.New("job weight must be between 1 and the number of workers")
+	ErrJobYielded           = errors.New("job yields its worker")
 )
 
-// Create a new generic pool with a given size
-func NewPool[T any](jobs, workers uint) *Pool[T] {
-	return &Pool[T]{
-		Jobs:            make([]func() (*T, error), 0, jobs),
-		MaxWorkers:      workers,
-		results:         make([]Result[T], 0, jobs),
-		results_chan:    make(chan Result[T], jobs),
-		panics:          make([]interface{}, 0, jobs),
-		panicC:          make(chan interface{}, jobs),
-		workerSemaphore: semaphore.NewWeighted(int64(workers)),
+// Create a new generic pool with a given size, jobs is only a hint and more
+// jobs may be added. Zero workers means one per CPU as reported by
+// runtime.GOMAXPROCS. Panics if an option is invalid.
+func NewPool[T any](jobs, workers uint, opts ...Option[T]) *Pool[T] {
+	p := &Pool[T]{
+		ctx:          context.Background(),
+		maxWorkers:   workers,
+		expectedJobs: jobs,
+		jobLogLevel:  slog.LevelDebug,
+		clock:        realClock{},
+	}
+	if err := p.apply(opts); err != nil {
+		panic(err)
+	}
+	p.opts = opts
+	p.init()
+	return p
+}
+
+// Create a pool for jobs that keep a CPU busy, running one worker per CPU
+func NewCPUBoundPool[T any](jobs uint, opts ...Option[T]) *Pool[T] {
+	return NewPool[T](jobs, 0, opts...)
+}
+
+// Most workers of a pool created by NewIOBoundPool
+const maxIOBoundWorkers = 1024
+
+// Create a pool for jobs that mostly wait on I/O, running multiplier
+// workers per CPU but at most 1024
+func NewIOBoundPool[T any](jobs, multiplier uint, opts ...Option[T]) *Pool[T] {
+	workers := uint(runtime.GOMAXPROCS(0)) * max(multiplier, 1)
+	return NewPool[T](jobs, min(workers, maxIOBoundWorkers), opts...)
+}
+
+// Create a new generic pool configured by options, without WithWorkers it
+// runs one worker per CPU
+func NewPoolWithOptions[T any](opts ...Option[T]) (*Pool[T], error) {
+	p := &Pool[T]{ctx: context.Background(), jobLogLevel: slog.LevelDebug, clock: realClock{}}
+	if err := p.apply(opts); err != nil {
+		return nil, err
+	}
+	p.opts = opts
+	p.init()
+	return p, nil
+}
+
+// Apply options and validate the resulting configuration
+func (p *Pool[T]) apply(opts []Option[T]) error {
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			return err
+		}
+	}
+	if p.serial {
+		if p.dynamic {
+			return fmt.Errorf("%w: a serial pool cannot accept jobs while running", ErrInvalidOption)
+		}
+		p.maxWorkers = 1
+	}
+	if p.adaptive != nil {
+		if p.serial {
+			return fmt.Errorf("%w: a serial pool cannot adapt its concurrency", ErrInvalidOption)
+		}
+		p.maxWorkers = p.adaptive.reset()
+	}
+	// A pool without workers would never start a job
+	if p.maxWorkers == 0 {
+		p.maxWorkers = uint(runtime.GOMAXPROCS(0))
+	}
+	return nil
+}
+
+// Allocate the internal state once the configuration is known
+func (p *Pool[T]) init() {
+	p.queue = make(jobQueue[T], 0, p.expectedJobs)
+	p.queued = sync.NewCond(&p.mu)
+	p.resumed = sync.NewCond(&p.mu)
+	p.dequeued = sync.NewCond(&p.mu)
+	p.workerLimit = newWorkerLimit(p.maxWorkers)
+}
+
+// Number of jobs added to the pool
+func (p *Pool[T]) NumJobs() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.added
+}
+
+// Name of the pool used to label its metrics, empty unless set WithName
+func (p *Pool[T]) Name() string {
+	return p.name
+}
+
+// Change the number of jobs the pool is expected to run, also while it
+// runs, like WithExpectedJobs. Sizes the storage of the queue for the jobs
+// still to be added and the storage of the results for n results.
+func (p *Pool[T]) SetExpectedJobs(n uint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.expectedJobs = n
+	if more := int(n) - p.added; more > 0 {
+		p.queue = slices.Grow(p.queue, more)
+	}
+	if p.running {
+		p.reserve()
 	}
 }
 
-// Add a job to the pool
-func (p *Pool[T]) AddJob(job func() (*T, error)) error {
+// Number of jobs waiting in the queue for a worker, without delayed jobs
+// and jobs waiting for their dependencies
+func (p *Pool[T]) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.queue)
+}
+
+// Number of jobs the queue holds as set WithQueueSize, 0 if it is
+// unbounded
+func (p *Pool[T]) Cap() int {
+	return p.queueSize
+}
+
+// Maximum number of jobs running at the same time
+func (p *Pool[T]) MaxWorkers() uint {
+	return p.workerLimit.limit()
+}
+
+// Change the number of jobs running at the same time, also while the pool
+// runs. More jobs start right away when growing, when shrinking running
+// jobs finish normally and no further job starts until fewer than n run.
+// Zero means one per CPU. Does nothing for a pool created WithSerial.
+func (p *Pool[T]) SetMaxWorkers(n uint) {
+	if p.serial {
+		return
+	}
+	if n == 0 {
+		n = uint(runtime.GOMAXPROCS(0))
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.maxWorkers = n
+	p.workerLimit.resize(n)
+	if !p.running || n <= p.workers {
+		return
+	}
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+	// The scheduler only hands out jobs while the workers are alive
+	p.spawnWorkers(n - p.workers)
+}
+
+// Start n more worker goroutines, the caller must hold the lock of the pool
+func (p *Pool[T]) spawnWorkers(n uint) {
+	p.workerGroup.Add(int(n))
+	for i := uint(0); i < n; i++ {
+		go p.runWorker(p.runCtx, p.schedulingCtx, p.work, p.nextWorkerID)
+		p.nextWorkerID++
+	}
+	p.workers += n
+}
+
+// Add a job to the pool, the handle can be used to wait for this job alone
+func (p *Pool[T]) AddJob(job func() (*T, error)) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{plain: job}, false)
+}
+
+// Add several jobs at once, either all of them or none if the pool does
+// not accept them or they do not fit into its queue
+func (p *Pool[T]) AddJobs(jobs ...func() (*T, error)) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 	if p.running {
-		return ErrAlreadyRunning
+		return p.startedErr()
+	}
+	if p.closed {
+		return ErrPoolClosed
+	}
+	if p.queueSize > 0 && len(p.queue)+len(jobs) > p.queueSize {
+		return ErrQueueFull
+	}
+	p.queue = slices.Grow(p.queue, len(jobs))
+	for _, job := range jobs {
+		p.enqueue(queuedJob[T]{plain: job})
+	}
+	return nil
+}
+
+// Add a job to the pool that receives the run context of the pool, which
+// is cancelled once the pool finishes or the context given to Run is done.
+// Jobs may be added concurrently until the pool starts running.
+func (p *Pool[T]) AddJobCtx(job func(ctx context.Context) (*T, error)) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{fn: job}, false)
+}
+
+// Add a job that is started before every queued job with a lower priority,
+// jobs with the same priority start in the order they were added
+func (p *Pool[T]) AddJobWithPriority(job func() (*T, error), priority int) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{plain: job, priority: priority}, false)
+}
+
+// Add a job that takes up weight worker slots while it runs, so that fewer
+// jobs run next to it. The weight may not exceed the number of workers.
+func (p *Pool[T]) AddJobWeighted(job func() (*T, error), weight int64) (*JobHandle[T], error) {
+	if weight < 1 || weight > int64(p.MaxWorkers()) {
+		return nil, ErrInvalidWeight
+	}
+	return p.add(queuedJob[T]{plain: job, weight: weight}, false)
+}
+
+// Add a job that returns its result by value, which saves allocating a
+// pointer for every job. Its result is only available through Value and
+// Get, Result stays nil.
+func (p *Pool[T]) AddJobV(job func() (T, error)) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{value: job}, false)
+}
+
+// Add a job with a name that labels its results, errors, panics, logs and
+// traces. Names do not need to be unique.
+func (p *Pool[T]) AddNamedJob(name string, job func() (*T, error)) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{plain: job, name: name}, false)
+}
+
+// Add a job whose context is cancelled after timeout, overriding
+// WithJobTimeout. The job is reported with ErrJobTimeout if it runs longer.
+func (p *Pool[T]) AddJobWithTimeout(job func(ctx context.Context) (*T, error), timeout time.Duration) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{fn: job, timeout: timeout}, false)
+}
+
+// Add a job to a pool created WithDynamicSubmission, also while it is
+// running. Without that option it behaves like AddJob.
+func (p *Pool[T]) Submit(job func() (*T, error)) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{plain: job}, true)
+}
+
+// Submit a job with a priority, see AddJobWithPriority
+func (p *Pool[T]) SubmitWithPriority(job func() (*T, error), priority int) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{plain: job, priority: priority}, true)
+}
+
+// Stop accepting jobs through Submit, the pool finishes once the jobs
+// submitted before are done. Closing is a no-op for pools that were not
+// created WithDynamicSubmission.
+func (p *Pool[T]) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	p.queued.Broadcast()
+	p.dequeued.Broadcast()
+	p.wakeDelays()
+}
+
+// Queue a job for the scheduler, submitted jobs are accepted while a
+// dynamic pool is running
+func (p *Pool[T]) add(job queuedJob[T], submit bool) (*JobHandle[T], error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.running && (!submit || !p.dynamic || p.state() == PoolDone) {
+		return nil, p.startedErr()
+	}
+	if p.closed {
+		return nil, ErrPoolClosed
+	}
+	for p.full() {
+		// Only a running pool makes room in its queue
+		if !submit || !p.running {
+			return nil, ErrQueueFull
+		}
+		p.dequeued.Wait()
+		if p.closed {
+			return nil, ErrPoolClosed
+		}
+	}
+	return p.enqueue(job), nil
+}
+
+// Add a job unless the queue is full or the pool no longer accepts jobs,
+// reports whether the job was added. Like Submit it accepts jobs while a
+// pool created WithDynamicSubmission is running.
+func (p *Pool[T]) TryAddJob(job func() (*T, error)) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.running && !p.dynamic || p.closed || p.full() {
+		return false
+	}
+	p.enqueue(queuedJob[T]{plain: job})
+	return true
+}
+
+// Whether the queue holds as many jobs as it may, the caller must hold the
+// lock of the pool
+func (p *Pool[T]) full() bool {
+	return p.queueSize > 0 && len(p.queue) >= p.queueSize
+}
+
+// Number and queue a job, the caller must hold the lock of the pool
+func (p *Pool[T]) enqueue(job queuedJob[T]) *JobHandle[T] {
+	job.index = p.nextIndex
+	if job.weight == 0 {
+		job.weight = 1
+	}
+	if job.name == "" {
+		job.name = strconv.Itoa(job.index)
+	}
+	job.handle = newJobHandle(p, job.index)
+	if job.group != "" {
+		if job.policy == nil {
+			job.policy = p.policies[job.group]
+		}
+		if p.groups == nil {
+			p.groups = make(map[string][]*JobHandle[T])
+		}
+		p.groups[job.group] = append(p.groups[job.group], job.handle)
+	}
+	job.addedAt = p.clock.Now()
+	switch {
+	case job.key != "" && p.follow(job):
+		// Reported once the job it follows finished
+	case len(job.deps) > 0:
+		p.waiting = append(p.waiting, waitingJob[T]{job: job})
+	case job.delay > 0 || job.held:
+		p.delayJob(job)
+	default:
+		p.push(job)
+	}
+	// Iterations of a repeated job are numbered up front
+	p.nextIndex = job.index + 1 + job.repeats
+	p.added += 1 + job.repeats
+	p.counters.added.Add(int64(1 + job.repeats))
+	p.queued.Signal()
+	return job.handle
+}
+
+// Adapt a job that does not observe the run context
+func ignoreContext[T any](job func() (*T, error)) func(ctx context.Context) (*T, error) {
+	return func(context.Context) (*T, error) {
+		return job()
+	}
+}
+
+// Run a job on a worker and return its result without recording it
+func (p *Pool[T]) runJob(ctx context.Context, job queuedJob[T], worker int) Result[T] {
+	startedAt := p.clock.Now()
+	queueWait := startedAt.Sub(job.addedAt)
+	if job.addedAt.Before(p.startedAt) {
+		queueWait = startedAt.Sub(p.startedAt)
+	}
+	timeout := job.timeout
+	if timeout == 0 {
+		timeout = p.jobTimeout
+	}
+	scope := &job.handle.scope
+	scope.info = JobInfo{Index: job.index, Name: job.name, Worker: worker}
+	scope.clock = p.clock
+	scope.pool = p
+	if !job.single {
+		scope.preemptEvery = p.preemptEvery
+	}
+	ctx = context.WithValue(ctx, jobInfoKey{}, scope)
+	beat := job.index
+	if job.single {
+		// Jobs run by Execute share their index
+		beat = p.adHocBeat()
+	}
+	p.track(beat, scope)
+	var stopStall func()
+	if p.onStall != nil {
+		stopStall = p.watchStall(ctx, job, startedAt, &scope.heartbeat)
+	}
+	var end func(Result[T])
+	if p.jobContext != nil {
+		p.callHook(ctx, job, func() { ctx, end = p.jobContext(ctx, job.index, job.name) })
+	}
+	// A job that yielded is still in flight, only its last run ends it
+	if p.collector != nil && !job.resumed {
+		p.collector.JobStarted(p.name)
+	}
+	if p.logger != nil && !job.resumed {
+		p.logJob(ctx, slog.LevelDebug, "job started", job.index, job.name)
+	}
+	if p.onJobStart != nil {
+		p.callHook(ctx, job, func() { p.onJobStart(job.index) })
+	}
+	var stopSlow func() bool
+	if p.onSlow != nil {
+		stopSlow = p.watchSlow(ctx, job, startedAt)
+	}
+	var out outcome[T]
+	if p.pprofLabels {
+		pprof.Do(ctx, pprof.Labels("pool", p.name, "job", job.name), func(ctx context.Context) {
+			out = p.executeTimed(ctx, job, timeout)
+		})
+	} else {
+		out = p.executeTimed(ctx, job, timeout)
+	}
+	duration := p.since(startedAt)
+	p.untrack(beat, scope)
+	if stopStall != nil {
+		stopStall()
+	}
+	slow := stopSlow != nil && stopSlow()
+	if scope.preemptEvery > 0 && out.panic == nil {
+		if next := continued[T](out.err); next != nil {
+			// Not the end of the job, which only reports its last run
+			job.handle.next = next
+			if end != nil {
+				yielded := Result[T]{Error: wrapError(out.err, job, out.attempts), Index: job.index, Name: job.name,
+					Meta: Meta{Worker: worker, Attempts: out.attempts, StartedAt: startedAt, Duration: duration}}
+				p.callHook(ctx, job, func() { end(yielded) })
+			}
+			return Result[T]{}
+		}
+	}
+	result := Result[T]{
+		Result:    out.result,
+		Error:     out.err,
+		OK:        out.err == nil && out.panic == nil,
+		Index:     job.index,
+		Name:      job.name,
+		Iteration: job.iteration,
+		Part:      scope.parts,
+		streamed:  job.streaming,
+		Job:       job.runner,
+		Policy:    job.policyName(),
+		Meta: Meta{
+			Warnings:         scope.warned(),
+			Worker:           worker,
+			Attempts:         out.attempts,
+			RetriesExhausted: out.exhausted,
+			StartedAt:        startedAt,
+			Duration:         duration,
+			Slow:             slow || stopSlow != nil && duration >= p.slowThreshold,
+			Hedged:           scope.won(),
+			QueueWait:        queueWait,
+		},
+	}
+	if out.panic != nil {
+		out.panic.StartedAt = startedAt
+		out.panic.Duration = duration
+		out.panic.Attempts = out.attempts
+		out.panic.HandlerPanic = p.handlePanic(ctx, *out.panic)
+		result.Result = nil
+		result.Warnings = nil
+		// The error keeps code that only checks errors from using a
+		// missing value
+		result.Error = &PanicError{Value: out.panic.Value, Stack: out.panic.Stack}
+		if !p.panicsAsErrors {
+			result.Panic = out.panic
+		}
+	} else if out.err == nil {
+		result.Value = out.value
+	}
+	result.Error = wrapError(result.Error, job, result.Attempts)
+	if !result.OK && job.counts() {
+		if p.fail(job) && p.firstError {
+			p.failWith(result.err())
+		}
+		if p.onFailure != nil {
+			p.onFailure(result.err())
+		}
+	}
+	if p.collector != nil {
+		p.collectEnd(result)
+	}
+	if p.logger != nil {
+		p.logResult(ctx, result)
+	}
+	if end != nil {
+		p.callHook(ctx, job, func() { end(result) })
+	}
+	if p.onJobEnd != nil {
+		p.callHook(ctx, job, func() { p.onJobEnd(job.index, result) })
+	}
+	if p.onError != nil && result.Error != nil && out.panic == nil {
+		p.callHook(ctx, job, func() { p.onError(job.index, job.name, result.Error) })
+	}
+	if p.cache != nil && job.key != "" {
+		p.remember(job, result)
+	}
+	return result
+}
+
+// Execute a job, failing it with ErrJobTimeout once timeout passed unless
+// it is zero
+func (p *Pool[T]) executeTimed(ctx context.Context, job queuedJob[T], timeout time.Duration) outcome[T] {
+	if timeout == 0 {
+		return p.execute(ctx, job)
+	}
+	jobCtx, cancel := p.withTimeout(ctx, timeout)
+	defer cancel()
+	out := p.executeWithin(jobCtx, job)
+	// Time ran out for the job rather than for the pool
+	if jobCtx.Err() != nil && ctx.Err() == nil && out.panic == nil {
+		out = outcome[T]{attempts: out.attempts, err: ErrJobTimeout}
+	}
+	return out
+}
+
+// What came out of executing a job
+type outcome[T any] struct {
+	result   *T
+	value    T
+	attempts int
+	err      error
+	panic    *PanicInfo
+	// Whether err would have been retried after the retries were used up
+	exhausted bool
+}
+
+// Execute a job on the current goroutine and recover its panic. A panicking
+// job is executed again as configured by WithPanicRetry, unless the pool
+// would skip it by now.
+func (p *Pool[T]) execute(ctx context.Context, job queuedJob[T]) (out outcome[T]) {
+	for panics := 0; ; panics++ {
+		out = p.executeOnce(ctx, job, out.attempts)
+		if out.panic == nil || panics >= p.panicRetries || p.skipped(ctx) != nil {
+			return out
+		}
+	}
+}
+
+// Execute a job once, or more often if it returns errors that are retried.
+// Its attempts are counted on top of the given ones.
+func (p *Pool[T]) executeOnce(ctx context.Context, job queuedJob[T], attempts int) (out outcome[T]) {
+	out.attempts = attempts
+	defer func() {
+		if r := recover(); r != nil {
+			out.panic = &PanicInfo{Value: r, Index: job.index, Name: job.name, Stack: debug.Stack()}
+			if hedged, ok := r.(*hedgePanic); ok {
+				out.panic.Value, out.panic.Stack = hedged.value, hedged.stack
+			}
+		}
+	}()
+	if len(p.middleware) > 0 {
+		var result *T
+		result, out.err, out.exhausted = attempt(p, ctx, job, p.wrap(job), &out.attempts)
+		if result != nil {
+			out.value = *result
+			if job.value == nil {
+				out.result = result
+			}
+		}
+		return out
+	}
+	if job.value != nil {
+		fn := func(context.Context) (T, error) {
+			return job.value()
+		}
+		out.value, out.err, out.exhausted = attempt(p, ctx, job, fn, &out.attempts)
+		return out
+	}
+	fn := job.fn
+	if job.plain != nil {
+		fn = func(context.Context) (*T, error) {
+			return job.plain()
+		}
+	}
+	out.result, out.err, out.exhausted = attempt(p, ctx, job, fn, &out.attempts)
+	if out.result != nil {
+		out.value = *out.result
+	}
+	return out
+}
+
+// Execute a job with a deadline. When detaching, the worker stops waiting
+// for the job once ctx is done and leaves it running in the background.
+func (p *Pool[T]) executeWithin(ctx context.Context, job queuedJob[T]) outcome[T] {
+	if !p.detachOnTimeout {
+		return p.execute(ctx, job)
+	}
+	done := make(chan outcome[T], 1)
+	go func() {
+		done <- p.execute(ctx, job)
+	}()
+	select {
+	case out := <-done:
+		return out
+	case <-ctx.Done():
+		return outcome[T]{err: ErrJobTimeout}
 	}
-	p.Jobs = append(p.Jobs, job)
+}
+
+// Pass a panic to the panic handler, a panicking handler must not take down
+// the worker. Returns the value the handler panicked with.
+func (p *Pool[T]) handlePanic(ctx context.Context, info PanicInfo) (handlerPanic interface{}) {
+	if p.panicHandler == nil {
+		return nil
+	}
+	defer func() {
+		if handlerPanic = recover(); handlerPanic != nil && p.logger != nil {
+			p.logJob(ctx, slog.LevelError, "panic handler panicked", info.Index, info.Name,
+				slog.Any("panic", handlerPanic))
+		}
+	}()
+	p.panicHandler(info)
 	return nil
 }
 
-func (p *Pool[T]) RunJob(job func() (*T, error)) {
+// Call a lifecycle hook of a job, a panic in the hook is passed to the
+// panic handler instead of taking down the worker
+func (p *Pool[T]) callHook(ctx context.Context, job queuedJob[T], hook func()) {
 	defer func() {
 		if r := recover(); r != nil {
-			p.panicC <- r
+			p.handlePanic(ctx, PanicInfo{Value: r, Index: job.index, Name: job.name, Stack: debug.Stack()})
 		}
-		p.workerSemaphore.Release(1)
-		p.workerGroup.Done()
 	}()
-	result, err := job()
-	p.results_chan <- Result[T]{Result: result, Error: err}
+	hook()
+}
+
+// Execute a job, retrying retryable errors until the retries are used up or
+// ctx is done. Counts the attempts even if the job panics. Reports whether
+// the retries were used up.
+func attempt[T, R any](p *Pool[T], ctx context.Context, job queuedJob[T], fn func(ctx context.Context) (R, error), attempts *int) (result R, err error, exhausted bool) {
+	start := *attempts
+	retries := p.retriesOf(job)
+	for {
+		*attempts++
+		result, err = fn(ctx)
+		if err == nil || retries <= 0 || ctx.Err() != nil || !p.retryable(ctx, job, err) {
+			return result, err, false
+		}
+		if *attempts-start > retries {
+			return result, err, true
+		}
+		if p.backoff == nil {
+			continue
+		}
+		timer := p.clock.NewTimer(p.backoff(*attempts - start))
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+			timer.Stop()
+			return result, err, false
+		}
+	}
+}
+
+// Whether an error of a job is retried, a panic in the predicate of
+// WithRetryIf is passed to the panic handler and means the error is not
+// retried
+func (p *Pool[T]) retryable(ctx context.Context, job queuedJob[T], err error) (retry bool) {
+	// A job that yields is not retried but continues later
+	if p.preemptEvery > 0 && continued[T](err) != nil {
+		return false
+	}
+	if p.retryIf == nil {
+		return true
+	}
+	p.callHook(ctx, job, func() { retry = p.retryIf(err) })
+	return retry
+}
+
+// Report a job that was never started
+func (p *Pool[T]) skip(job queuedJob[T], err error) {
+	// A cancelled job is reported as such by whoever holds it, any other job
+	// can no longer be cancelled
+	if job.handle.state.Swap(jobStarted) == jobCancelled {
+		err = ErrJobCancelled
+	}
+	result := Result[T]{Error: wrapError(err, job, 0), Index: job.index, Name: job.name, Iteration: job.iteration, Job: job.runner, Policy: job.policyName(), Skipped: true, Meta: Meta{Worker: -1}}
+	if err == ErrPoolStopped {
+		p.stopSkipped.Add(1)
+	}
+	p.retain(&result, job)
+	job.handle.complete(result, true)
+	p.collect(result, true)
+	p.settle(job, result)
+	for _, follower := range p.followers(job) {
+		p.skip(follower, err)
+	}
+}
+
+// Report a job that returned or panicked
+func (p *Pool[T]) emit(job queuedJob[T], result Result[T]) {
+	p.retain(&result, job)
+	job.handle.complete(result, false)
+	p.collect(result, false)
+	p.settle(job, result)
+	p.fanOut(job, result)
 }
 
-// Run the pool
-func (p *Pool[T]) Run() {
+// Report the jobs of a batch at once, taking the lock only once
+func (p *Pool[T]) emitBatch(jobs []queuedJob[T], results []Result[T]) {
+	if len(results) == 0 {
+		return
+	}
+	for i := range results {
+		p.retain(&results[i], jobs[i])
+	}
+	for i, result := range results {
+		jobs[i].handle.complete(result, false)
+		if p.stream != nil {
+			p.send(result)
+		}
+	}
+	p.mu.Lock()
+	for _, result := range results {
+		p.record(result, false)
+	}
+	p.notifyProgress()
+	p.mu.Unlock()
+	for i, result := range results {
+		p.settle(jobs[i], result)
+		p.fanOut(jobs[i], result)
+	}
+}
+
+// Hand a result to the stream if someone subscribed, or keep it for Wait
+func (p *Pool[T]) collect(result Result[T], skipped bool) {
+	if p.stream != nil {
+		p.send(result)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.record(result, skipped)
+	p.notifyProgress()
+}
+
+// Hand a result to the stream, or drop it once the pool is done, which
+// before the last job returned means Drain abandoned the job and nobody
+// may read the stream anymore
+func (p *Pool[T]) send(result Result[T]) {
+	select {
+	case p.stream <- result:
+	case <-p.done:
+	}
+}
+
+// Count a result and keep it for Wait unless it was streamed, a panic is
+// also kept apart from the results. Results of jobs abandoned by Drain are
+// only counted. The caller must hold the lock of the pool.
+func (p *Pool[T]) record(result Result[T], skipped bool) {
+	p.finished++
+	if p.recorded != nil {
+		close(p.recorded)
+		p.recorded = nil
+	}
+	switch {
+	case skipped:
+		p.counters.skipped.Add(1)
+	case result.Panic != nil:
+		p.counters.panicked.Add(1)
+		if p.abandoned {
+			break
+		}
+		if p.firstPanic == nil {
+			p.firstPanic = result.Panic
+		}
+		if p.stream == nil {
+			p.panics = append(p.panics, *result.Panic)
+		}
+	case result.Error != nil:
+		p.counters.failed.Add(1)
+	default:
+		p.counters.completed.Add(1)
+	}
+	if p.errorFeed != nil && result.Error != nil && result.Panic == nil {
+		p.errorFeed.push(result.Error)
+	}
+	if p.stream == nil {
+		p.keep(result)
+	}
+}
+
+// Stream results as jobs finish, the channel is closed once the last job
+// completed. Must be called before Run, results delivered through the
+// stream are not returned by Wait and panics arrive as results with Panic
+// set. Calling it after the pool started without subscribing, or on a pool
+// created WithDiscardResults, returns a closed channel.
+func (p *Pool[T]) Results() <-chan Result[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stream == nil {
+		if p.running || p.discardResults {
+			closed := make(chan Result[T])
+			close(closed)
+			return closed
+		}
+		p.stream = make(chan Result[T], p.maxWorkers)
+	}
+	return p.stream
+}
+
+// Mark the pool as failed by a job, workers observe it before starting
+// their next job. Reports whether the pool fails fast because of the job.
+func (p *Pool[T]) fail(job queuedJob[T]) bool {
+	failFast := p.failFast
+	if job.policy != nil {
+		failFast = job.policy.FailFast
+	}
+	if failFast {
+		p.failed.Store(true)
+	}
+	if p.maxErrors > 0 && p.errorCount.Add(1) >= int64(p.maxErrors) {
+		p.tooManyErrors.Store(true)
+	}
+	return failFast
+}
+
+// Keep the first error of a pool created WithFirstError and cancel the
+// context of the run with it
+func (p *Pool[T]) failWith(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.firstErr == nil {
+		p.firstErr = err
+		p.cancel(err)
+	}
+}
+
+// Start the pool without waiting for it, a pool can only be run once.
+// Returns ErrAlreadyRunning while it runs and ErrPoolFinished once it is
+// done, until it is Reset.
+func (p *Pool[T]) Run() error {
+	return p.RunWithContext(p.ctx)
+}
+
+// Start the pool without waiting for it, jobs that have not acquired a
+// worker once ctx is done are skipped and reported with ctx.Err()
+func (p *Pool[T]) RunWithContext(ctx context.Context) error {
+	_, err := p.start(ctx)
+	return err
+}
+
+// Derive a context from base that is also cancelled once ctx is done, with
+// the cause of ctx
+func mergeCancel(base, ctx context.Context) (context.Context, context.CancelFunc) {
+	merged, cancel := context.WithCancelCause(base)
+	stop := context.AfterFunc(ctx, func() {
+		cancel(context.Cause(ctx))
+	})
+	return merged, func() {
+		stop()
+		cancel(nil)
+	}
+}
+
+// Deadline of the run that started at startedAt, zero if it has none. The
+// caller must hold the lock of the pool.
+func (p *Pool[T]) deadlineAt() time.Time {
+	deadline := p.deadline
+	if p.timeout > 0 {
+		if end := p.startedAt.Add(p.timeout); deadline.IsZero() || end.Before(deadline) {
+			deadline = end
+		}
+	}
+	return deadline
+}
+
+// Start the pool unless it already started, reports whether it started.
+// The error is ErrAlreadyRunning or ErrPoolFinished if it did not, and
+// otherwise describes dependencies that can never be met, the jobs behind
+// it are skipped while the others run.
+func (p *Pool[T]) start(ctx context.Context) (bool, error) {
+	p.mu.Lock()
+	if p.running {
+		err := p.startedErr()
+		p.mu.Unlock()
+		return false, err
+	}
 	p.running = true
-	// Also take semaphore into account
-	for i := range p.Jobs {
-		if err := p.workerSemaphore.Acquire(context.Background(), 1); err != nil {
-			// Add to failed jobs
-			p.results_chan <- Result[T]{Error: ErrAcquiringSemaphore}
+	blocked, err := p.resolveDependencies()
+	p.hasDependencies = len(p.waiting) > 0
+	if !p.dynamic && !p.feeding {
+		p.closed = true
+	}
+	p.startedAt = p.clock.Now()
+	p.counters.startedAt.Store(p.startedAt.UnixNano())
+	p.scheduleDelays()
+	stopBase := func() {}
+	if p.baseCtx != nil && p.baseCtx != ctx {
+		ctx, stopBase = mergeCancel(p.baseCtx, ctx)
+	}
+	stopDeadline := func() {}
+	if deadline := p.deadlineAt(); !deadline.IsZero() {
+		ctx, stopDeadline = p.withDeadline(ctx, deadline, ErrPoolDeadlineExceeded)
+	}
+	ctx, p.cancel = context.WithCancelCause(ctx)
+	scheduling, stop := context.WithCancel(ctx)
+	p.stopScheduling = stop
+	p.done = make(chan struct{})
+	p.exited = make(chan struct{})
+	// Wake up a paused scheduler and workers so they skip the remaining jobs
+	context.AfterFunc(scheduling, func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		p.resumed.Broadcast()
+	})
+	stopProgress := func() {}
+	if p.onProgress != nil {
+		stopProgress = p.reportProgress()
+	}
+	jobs, workers := p.added, p.maxWorkers
+	started := func() {
+		// Log outside of the lock before the first job is handed out
+		if p.logger != nil {
+			p.logPool(ctx, "pool started", slog.Int("jobs", jobs), slog.Uint64("workers", uint64(workers)))
+		}
+		p.skipBlocked(blocked)
+	}
+	finished := func() {
+		stop()
+		p.delayGroup.Wait()
+		p.mu.Lock()
+		p.endCause = p.haltCause(ctx)
+		p.mu.Unlock()
+		// Only detached jobs are still running by now
+		if p.stopped.Load() {
+			p.cancel(ErrPoolStopped)
+		} else {
+			p.cancel(ErrPoolFinished)
+		}
+		stopDeadline()
+		stopBase()
+		p.counters.doneAt.Store(p.clock.Now().UnixNano())
+		if p.logger != nil {
+			p.logFinished(context.WithoutCancel(ctx))
+		}
+		stopProgress()
+		if p.stream != nil {
+			close(p.stream)
+		}
+		if p.errorFeed != nil {
+			p.errorFeed.close()
+		}
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		close(p.exited)
+		if !p.abandoned {
+			close(p.done)
+		}
+	}
+	p.runCtx, p.schedulingCtx = ctx, scheduling
+	p.reserve()
+	if p.errorFeed != nil {
+		go p.errorFeed.run()
+	}
+	// A dynamic pool may be handed delayed jobs until it is closed
+	if len(p.delays.jobQueue) > 0 || p.dynamic {
+		p.delayWake = make(chan struct{}, 1)
+		p.delayGroup.Add(1)
+		go p.releaseDelays(scheduling, p.delayWake)
+	}
+	if p.serial {
+		p.mu.Unlock()
+		started()
+		p.runSerial(ctx, scheduling)
+		finished()
+		return true, err
+	}
+	work := make(chan dispatch[T])
+	p.work = work
+	p.workers = 0
+	p.nextWorkerID = 0
+	p.spawnWorkers(p.maxWorkers)
+	go func() {
+		started()
+		p.schedule(scheduling, work)
+	}()
+	go func() {
+		p.workerGroup.Wait()
+		finished()
+	}()
+	p.mu.Unlock()
+	return true, err
+}
+
+// Run every job on the current goroutine one after the other, in the order
+// the scheduler would hand them out
+func (p *Pool[T]) runSerial(ctx, scheduling context.Context) {
+	if p.workerInit != nil {
+		var teardown func()
+		var ok bool
+		if ctx, teardown, ok = p.initWorker(ctx, 0); !ok {
+			return
+		}
+		defer teardown()
+	}
+	for {
+		job, ok := p.next(scheduling)
+		if !ok {
+			return
+		}
+		if err := p.skipped(scheduling); err != nil {
+			p.skip(job, err)
+			continue
+		}
+		if p.fromCache(job) {
+			continue
+		}
+		if err := p.throttle(scheduling); err != nil {
+			p.skip(job, p.skipped(scheduling))
+			continue
+		}
+		weight, err := p.acquire(scheduling, job.weight)
+		if err != nil {
+			p.skip(job, err)
+			continue
+		}
+		if result, ok := p.begin(ctx, scheduling, job, 0); ok {
+			p.emit(job, result)
+		}
+		p.release(weight)
+	}
+}
+
+// Hand every job to the next idle worker, the workers stop once the queue
+// is closed and drained
+func (p *Pool[T]) schedule(ctx context.Context, work chan<- dispatch[T]) {
+	defer close(work)
+	for {
+		job, ok := p.next(ctx)
+		if !ok {
+			return
+		}
+		if err := p.skipped(ctx); err != nil {
+			p.skip(job, err)
+			continue
+		}
+		if p.fromCache(job) {
+			continue
+		}
+		// Waiting only fails once ctx is done
+		if err := p.throttle(ctx); err != nil {
+			p.skip(job, p.skipped(ctx))
+			continue
+		}
+		d := dispatch[T]{job: job, weight: job.weight}
+		if p.batchSize > 1 && job.weight == 1 {
+			d.batch = p.fillBatch(ctx, job)
+		}
+		// Also take the worker limit into account, a worker is always idle
+		// once the weight was acquired since every running job holds some
+		p.counters.waiting.Add(1)
+		held, err := p.acquire(ctx, d.weight)
+		p.counters.waiting.Add(-1)
+		if err != nil {
+			p.skipDispatch(d, err)
+			continue
+		}
+		d.weight = held
+		if p.idleTimeout > 0 && p.tryHand(work, d) {
 			continue
 		}
-		p.workerGroup.Add(1)
-		go p.RunJob(p.Jobs[i])
+		select {
+		case work <- d:
+		case <-ctx.Done():
+			p.release(d.weight)
+			p.skipDispatch(d, p.skipped(ctx))
+		}
+		if p.idleTimeout > 0 {
+			p.mu.Lock()
+			p.handing = false
+			p.mu.Unlock()
+		}
+	}
+}
+
+// Jobs handed to a worker at once, a batch takes a single worker
+type dispatch[T any] struct {
+	job queuedJob[T]
+	// Set instead of job when batching
+	batch  []queuedJob[T]
+	weight int64
+}
+
+// Take further jobs of weight 1 that are already queued to batch them with
+// job, up to the batch size
+func (p *Pool[T]) fillBatch(ctx context.Context, job queuedJob[T]) []queuedJob[T] {
+	batch := make([]queuedJob[T], 1, p.batchSize)
+	batch[0] = job
+	for len(batch) < p.batchSize {
+		p.mu.Lock()
+		if len(p.queue) == 0 || p.queue[0].weight != 1 || p.paused {
+			p.mu.Unlock()
+			break
+		}
+		next := p.take()
+		p.dequeued.Broadcast()
+		p.mu.Unlock()
+		if p.fromCache(next) {
+			continue
+		}
+		if err := p.throttle(ctx); err != nil {
+			p.skip(next, p.skipped(ctx))
+			break
+		}
+		batch = append(batch, next)
+	}
+	return batch
+}
+
+// Acquire weight for a dispatch, waiting at most as long as set
+// WithAcquireTimeout. The error is why the jobs of the dispatch are skipped.
+func (p *Pool[T]) acquire(ctx context.Context, weight int64) (int64, error) {
+	acquireCtx := ctx
+	if p.acquireTimeout > 0 {
+		var cancel context.CancelFunc
+		acquireCtx, cancel = p.withTimeout(ctx, p.acquireTimeout)
+		defer cancel()
+	}
+	held, err := p.hold(acquireCtx, weight)
+	if err == nil {
+		return held, nil
+	}
+	// Once ctx is done every remaining job is skipped for the same reason
+	// without waiting
+	if err := p.skipped(ctx); err != nil {
+		return 0, err
+	}
+	if acquireCtx.Err() != nil {
+		return 0, fmt.Errorf("%w within %v", ErrAcquiringSemaphore, p.acquireTimeout)
+	}
+	return 0, fmt.Errorf("%w: %w", ErrAcquiringSemaphore, err)
+}
+
+// Take weight from the worker limit and the limiter shared WithLimiter
+func (p *Pool[T]) hold(ctx context.Context, weight int64) (int64, error) {
+	held, err := p.workerLimit.acquire(ctx, weight)
+	if err == nil && p.sharedLimit != nil {
+		if err = p.sharedLimit.Acquire(ctx, held); err != nil {
+			p.workerLimit.release(held)
+		}
+	}
+	return held, err
+}
+
+// Give back weight taken by acquire or hold
+func (p *Pool[T]) release(weight int64) {
+	if p.sharedLimit != nil {
+		p.sharedLimit.Release(weight)
+	}
+	p.workerLimit.release(weight)
+}
 
+// Skip every job of a dispatch
+func (p *Pool[T]) skipDispatch(d dispatch[T], err error) {
+	if d.batch == nil {
+		p.skip(d.job, err)
+		return
+	}
+	for _, job := range d.batch {
+		p.skip(job, err)
 	}
 }
 
-// Wait for the pool to finish
-func (p *Pool[T]) Wait() (results []Result[T], panics []interface{}) {
-	p.Run()
-	p.workerGroup.Wait()
-	close(p.results_chan)
-	close(p.panicC)
-	for result := range p.results_chan {
-		p.results = append(p.results, result)
+// Wait until the rate limit allows starting another job
+func (p *Pool[T]) throttle(ctx context.Context) error {
+	if p.limiter == nil {
+		return nil
+	}
+	now := p.clock.Now()
+	reservation := p.limiter.ReserveN(now, 1)
+	delay := reservation.DelayFrom(now)
+	if delay == 0 {
+		return nil
+	}
+	timer := p.clock.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C():
+		return nil
+	case <-ctx.Done():
+		reservation.CancelAt(p.clock.Now())
+		return ctx.Err()
 	}
-	for panic := range p.panicC {
-		p.panics = append(p.panics, panic)
+}
+
+// Run jobs with ctx until the scheduler runs out of them, a panicking job
+// is recovered in runJob and does not stop the worker
+func (p *Pool[T]) runWorker(ctx, scheduling context.Context, work <-chan dispatch[T], id int) {
+	defer p.workerGroup.Done()
+	if p.workerInit != nil {
+		var teardown func()
+		var ok bool
+		if ctx, teardown, ok = p.initWorker(ctx, id); !ok {
+			return
+		}
+		defer teardown()
+	}
+	var jobs []queuedJob[T]
+	var results []Result[T]
+	var idle Timer
+	if p.idleTimeout > 0 {
+		idle = p.clock.NewTimer(p.idleTimeout)
+		defer idle.Stop()
+	}
+	for {
+		d, ok := p.receive(work, idle)
+		if !ok {
+			return
+		}
+		p.awaitResume(scheduling)
+		if d.batch == nil {
+			if result, ok := p.begin(ctx, scheduling, d.job, id); ok {
+				p.emit(d.job, result)
+			}
+		} else {
+			// Report the results of a batch together
+			jobs, results = jobs[:0], results[:0]
+			for _, job := range d.batch {
+				if result, ok := p.begin(ctx, scheduling, job, id); ok {
+					jobs = append(jobs, job)
+					results = append(results, result)
+				}
+			}
+			p.emitBatch(jobs, results)
+		}
+		p.release(d.weight)
 	}
-	return p.results, p.panics
+}
+
+// Wait for the next dispatch, reports false once the scheduler is done or
+// the worker was idle for too long and may retire
+func (p *Pool[T]) receive(work <-chan dispatch[T], idle Timer) (dispatch[T], bool) {
+	if idle == nil {
+		d, ok := <-work
+		return d, ok
+	}
+	for {
+		idle.Reset(p.idleTimeout)
+		select {
+		case d, ok := <-work:
+			return d, ok
+		case <-idle.C():
+			if p.retire() {
+				return dispatch[T]{}, false
+			}
+		}
+	}
+}
+
+// Let an idle worker exit unless it is the last one, which stays to pick up
+// the next job, or the scheduler counts on it for the job it is handing out
+func (p *Pool[T]) retire() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.workers <= 1 || p.handing {
+		return false
+	}
+	p.workers--
+	return true
+}
+
+// Hand d to an idle worker if there is one, otherwise start another worker
+// to pick it up if workers retired before. Reports false if the caller has
+// to wait for a worker, which until then keeps every worker from retiring.
+func (p *Pool[T]) tryHand(work chan<- dispatch[T], d dispatch[T]) bool {
+	select {
+	case work <- d:
+		return true
+	default:
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.workers < p.maxWorkers {
+		p.spawnWorkers(1)
+	}
+	p.handing = true
+	return false
+}
+
+// Run a job handed to a worker unless it should be skipped, in which case
+// it is reported right away and ok is false
+func (p *Pool[T]) begin(ctx, scheduling context.Context, job queuedJob[T], worker int) (result Result[T], ok bool) {
+	// Handing over the job may win the race against a cancellation
+	if err := p.skipped(scheduling); err != nil {
+		p.skip(job, err)
+		return result, false
+	}
+	// A job that yielded started already
+	if !job.resumed && !job.handle.start() {
+		p.skip(job, ErrJobCancelled)
+		return result, false
+	}
+	if p.breaker != nil && !p.breaker.allow(p.dynamic, p.clock.Now()) {
+		p.skip(job, ErrCircuitOpen)
+		return result, false
+	}
+	p.counters.running.Add(1)
+	defer p.counters.running.Add(-1)
+	if p.preemptEvery > 0 {
+		// The scheduler waits for jobs that may yield before it finishes
+		p.mu.Lock()
+		p.blockedOn++
+		p.mu.Unlock()
+	}
+	result = p.runJob(ctx, job, worker)
+	if p.preemptEvery > 0 && p.resume(job) {
+		return result, false
+	}
+	if p.breaker != nil {
+		p.breaker.record(result.OK, p.clock.Now())
+	}
+	if p.adaptive != nil {
+		p.adapt(ctx, job, result)
+	}
+	return result, true
+}
+
+// Stop starting jobs until Resume is called, running jobs finish normally.
+// A pool paused after it started its last job is done once that job
+// finished.
+func (p *Pool[T]) Pause() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.active() {
+		return p.notActiveErr()
+	}
+	p.paused = true
+	return nil
+}
+
+// Continue starting jobs after Pause
+func (p *Pool[T]) Resume() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.state() == PoolDone {
+		return ErrPoolFinished
+	}
+	if !p.paused {
+		return ErrNotPaused
+	}
+	p.paused = false
+	p.resumed.Broadcast()
+	return nil
+}
+
+// Whether the pool started and has not finished yet, must be called with
+// the lock held
+func (p *Pool[T]) active() bool {
+	if !p.running {
+		return false
+	}
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Block a worker while the pool is paused
+func (p *Pool[T]) awaitResume(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for p.paused && ctx.Err() == nil {
+		p.resumed.Wait()
+	}
+}
+
+// Take the next job off the queue, blocking until one was submitted or the
+// pool was closed
+func (p *Pool[T]) next(ctx context.Context) (queuedJob[T], bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for {
+		// A paused pool still finishes once there is no job left to start
+		left := len(p.queue) > 0 || !p.closed || p.blockedOn > 0
+		if p.paused && ctx.Err() == nil && left {
+			p.resumed.Wait()
+			continue
+		}
+		// Waiting jobs are queued once their dependencies succeeded
+		if len(p.queue) == 0 && (!p.closed || p.blockedOn > 0) {
+			p.queued.Wait()
+			continue
+		}
+		break
+	}
+	if len(p.queue) == 0 {
+		return queuedJob[T]{}, false
+	}
+	// Let a feeding goroutine know there is room in the queue
+	p.dequeued.Broadcast()
+	return p.take(), true
+}
+
+// Reason for not starting any further jobs, if any
+func (p *Pool[T]) skipped(ctx context.Context) error {
+	if p.stopped.Load() {
+		return ErrPoolStopped
+	}
+	// Failing fast cancels the context WithFirstError, the jobs are still
+	// skipped because of the failure
+	if p.failed.Load() {
+		return ErrFailFastTriggered
+	}
+	if ctx.Err() != nil {
+		return causeOf(ctx)
+	}
+	if p.tooManyErrors.Load() {
+		return ErrTooManyErrors
+	}
+	return nil
+}
+
+// Error of a context that is done wrapping the cause it was cancelled with,
+// or only the cause once the deadline of the pool passed
+func causeOf(ctx context.Context) error {
+	err, cause := ctx.Err(), context.Cause(ctx)
+	if cause == err {
+		return err
+	}
+	if errors.Is(cause, ErrPoolDeadlineExceeded) {
+		return cause
+	}
+	return fmt.Errorf("%w: %w", err, cause)
+}
+
+// Why the run stopped starting jobs early as reported by CancelCause, the
+// caller must hold the lock of the pool
+func (p *Pool[T]) haltCause(ctx context.Context) error {
+	switch {
+	case p.stopped.Load():
+		return ErrPoolStopped
+	case p.failed.Load():
+		return ErrFailFastTriggered
+	case ctx.Err() != nil:
+		return causeOf(ctx)
+	case p.tooManyErrors.Load():
+		return ErrTooManyErrors
+	}
+	return nil
+}
+
+// Why the pool stopped starting jobs before all of them ran, nil while it
+// runs as usual, if it ran every job or was never started. That is
+// ErrPoolStopped, ErrFailFastTriggered or ErrTooManyErrors, or the error of
+// the context of the run wrapping its cause, ErrPoolDeadlineExceeded for
+// the deadline of the pool.
+func (p *Pool[T]) CancelCause() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.running {
+		return nil
+	}
+	select {
+	case <-p.exited:
+		return p.endCause
+	default:
+	}
+	return p.haltCause(p.runCtx)
+}
+
+// Return a pool that finished, or was never started, to its initial state
+// so another batch can be run with the same configuration. Returns
+// ErrAlreadyRunning while jobs are still in flight.
+func (p *Pool[T]) Reset() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.running {
+		select {
+		case <-p.exited:
+		default:
+			return ErrAlreadyRunning
+		}
+	}
+	// Results handed out by Wait stay untouched, so start with fresh
+	// storage
+	p.queue = make(jobQueue[T], 0, p.added)
+	p.delays = delayQueue[T]{}
+	p.delayWake = nil
+	p.results = nil
+	p.oldest = 0
+	p.panics = nil
+	p.groups = nil
+	p.keyed = nil
+	p.rounds = nil
+	p.round = 0
+	p.waiting = nil
+	p.dependents = nil
+	p.blockedOn = 0
+	p.hasDependencies = false
+	p.added = 0
+	p.nextIndex = 0
+	p.retried = nil
+	p.finished = 0
+	p.sorted = false
+	p.firstPanic = nil
+	p.firstErr = nil
+	p.onFailure = nil
+	p.counters.reset()
+	p.closed = false
+	p.paused = false
+	p.feeding = false
+	p.running = false
+	p.stream = nil
+	p.errorFeed = nil
+	p.done = nil
+	p.exited = nil
+	p.abandoned = false
+	p.drainErr = nil
+	p.endCause = nil
+	p.cancel = nil
+	p.stopScheduling = nil
+	p.failed.Store(false)
+	p.errorCount.Store(0)
+	p.tooManyErrors.Store(false)
+	if p.breaker != nil {
+		p.breaker.reset()
+	}
+	if p.adaptive != nil {
+		p.maxWorkers = p.adaptive.reset()
+		p.workerLimit.resize(p.maxWorkers)
+	}
+	p.stopped.Store(false)
+	p.stopSkipped.Store(0)
+	return nil
+}
+
+// Wait for the pool to finish, results are ordered by job index and do
+// not include those already delivered through Results. Every job has a
+// result, a job that panicked has Panic set and a *PanicError as its error
+// and is also listed in panics. A pool that was not started yet is run
+// first. Wait may be called from several goroutines at once and again
+// later, every caller receives copies it may modify, see WaitInto to avoid
+// allocating them.
+func (p *Pool[T]) Wait() (results []Result[T], panics []PanicInfo) {
+	return p.WaitWithContext(p.ctx)
+}
+
+// Wait for the pool to finish, running it with ctx if it was not started
+// yet
+func (p *Pool[T]) WaitWithContext(ctx context.Context) (results []Result[T], panics []PanicInfo) {
+	p.waitSorted(ctx)
+	defer p.mu.Unlock()
+	return slices.Clone(p.results), slices.Clone(p.panics)
+}
+
+// Wait for the pool to finish like Wait and append the results to dst,
+// which is returned like append does, so a caller can reuse the storage
+// of the results of an earlier run. Panics are left to Panics.
+func (p *Pool[T]) WaitInto(dst []Result[T]) []Result[T] {
+	p.waitSorted(p.ctx)
+	defer p.mu.Unlock()
+	return append(dst, p.results...)
+}
+
+// Wait for the pool to finish and sort its results, returns with the lock
+// of the pool held
+func (p *Pool[T]) waitSorted(ctx context.Context) {
+	p.start(ctx)
+	<-p.doneChan()
+	p.mu.Lock()
+	if !p.sorted {
+		sortResults(p.results)
+		sort.Slice(p.panics, func(i, j int) bool {
+			return p.panics[i].Index < p.panics[j].Index
+		})
+		p.sorted = true
+	}
+	if p.propagatePanics && p.firstPanic != nil {
+		info := *p.firstPanic
+		p.mu.Unlock()
+		panic(&PropagatedPanic{Info: info})
+	}
+}
+
+// Wait for the pool to finish like Wait and join the errors of all jobs
+// into one error with errors.Join, nil if every job succeeded. Each job
+// error is a *JobError, the error of a panic wraps a *PanicError.
+// WithFirstError the error is only the first one.
+func (p *Pool[T]) WaitErr() ([]Result[T], error) {
+	results, _ := p.Wait()
+	if p.firstError {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return results, p.firstErr
+	}
+	return results, joinErrors(results)
+}
+
+// Wait for the pool to finish like Wait and return the values of all jobs
+// in job order, for scripts and tests. Panics with an error joining the
+// errors and panics of all jobs, each a *JobError, if any job did not
+// succeed. Like Wait it may be called again and returns the same values.
+func (p *Pool[T]) MustWait() []T {
+	results, _ := p.Wait()
+	if errs := Results[T](results).Failures(); len(errs) > 0 {
+		panic(fmt.Errorf("%d of %d jobs failed:\n%w", len(errs), len(results), errors.Join(errs...)))
+	}
+	return Results[T](results).Successes()
+}
+
+// Run the pool with ctx and return the result of the first job that
+// succeeds, then cancel the context of the run so the other jobs stop.
+// Results of the other jobs are discarded. Must be called before the pool
+// starts, like Results. If no job succeeds the error joins ErrNoSuccess
+// with the errors of all jobs, if ctx is done first it is ctx.Err().
+func (p *Pool[T]) WaitFirst(ctx context.Context) (Result[T], error) {
+	results := p.Results()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if started, err := p.start(ctx); !started {
+		return Result[T]{}, err
+	}
+	// Keep draining so the workers of the remaining jobs never block on the
+	// stream
+	defer func() {
+		go func() {
+			for range results {
+			}
+		}()
+	}()
+	errs := []error{ErrNoSuccess}
+	for {
+		select {
+		case result, ok := <-results:
+			if !ok {
+				return Result[T]{}, errors.Join(errs...)
+			}
+			if result.OK {
+				return result, nil
+			}
+			errs = append(errs, result.err())
+		case <-ctx.Done():
+			return Result[T]{}, ctx.Err()
+		}
+	}
+}
+
+// Wait until at least n jobs finished, succeeded or not, running the pool
+// with ctx if it was not started yet. Returns the results collected by then
+// ordered by job index, with panics as results with Panic set, while the
+// other jobs keep running and are picked up by a later call to Wait. Waiting
+// for all jobs waits until the pool is done. Returns ErrNotEnoughJobs if n
+// is more than the jobs added so far, and ctx.Err() together with the
+// results collected so far if ctx is done first.
+func (p *Pool[T]) WaitN(ctx context.Context, n int) ([]Result[T], error) {
+	p.mu.Lock()
+	total := p.added
+	p.mu.Unlock()
+	if n < 0 || n > total {
+		return nil, fmt.Errorf("%w: waiting for %d of %d jobs", ErrNotEnoughJobs, n, total)
+	}
+	p.start(ctx)
+	if n == total {
+		select {
+		case <-p.doneChan():
+			results, _ := p.Wait()
+			return results, nil
+		case <-ctx.Done():
+		}
+	}
+	for n < total {
+		p.mu.Lock()
+		if p.finished >= n {
+			p.mu.Unlock()
+			return p.collected(), nil
+		}
+		if p.recorded == nil {
+			p.recorded = make(chan struct{})
+		}
+		recorded := p.recorded
+		p.mu.Unlock()
+		select {
+		case <-recorded:
+		case <-ctx.Done():
+			return p.collected(), ctx.Err()
+		}
+	}
+	return p.collected(), ctx.Err()
+}
+
+// Results collected so far ordered by job index
+func (p *Pool[T]) collected() []Result[T] {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	results := append([]Result[T](nil), p.results...)
+	sortResults(results)
+	return results
+}
+
+// Order results by job index, the values emitted by a streaming job in the
+// order they were emitted before its final result
+func sortResults[T any](results []Result[T]) {
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Index != results[j].Index {
+			return results[i].Index < results[j].Index
+		}
+		return results[i].Part < results[j].Part
+	})
+}
+
+// Join the errors of results
+func joinErrors[T any](results []Result[T]) error {
+	var errs []error
+	for _, result := range results {
+		if result.Error != nil {
+			errs = append(errs, result.Error)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Wrap the error of a job, if any
+func wrapError[T any](err error, job queuedJob[T], attempts int) error {
+	if err == nil {
+		return nil
+	}
+	return &JobError{Index: job.index, Name: job.name, Attempt: attempts, Err: err}
+}
+
+// Whether err is or wraps a *PanicError
+func isPanicError(err error) bool {
+	var panicErr *PanicError
+	return errors.As(err, &panicErr)
+}
+
+// Wait for the pool to finish for at most d, running it first if it was not
+// started yet. On timeout the results and
+// panics collected so far are returned together with the number of jobs
+// that have not finished yet and ErrWaitTimeout. Those jobs keep running
+// and are picked up by a later call to Wait.
+func (p *Pool[T]) WaitWithTimeout(d time.Duration) (results []Result[T], panics []PanicInfo, outstanding int, err error) {
+	p.start(p.ctx)
+	timer := p.clock.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-p.doneChan():
+		results, panics = p.Wait()
+		return results, panics, 0, nil
+	case <-timer.C():
+	}
+	results, panics, outstanding = p.snapshot()
+	return results, panics, outstanding, ErrWaitTimeout
+}
+
+// Stop starting jobs and wait for the running ones to finish or ctx to be
+// done. Returns the results collected so far, which include the jobs that
+// never started with ErrPoolStopped, the number of those jobs and ctx.Err()
+// if jobs were still running. Wait returns once the running jobs finished.
+// Stopping again returns the current state.
+func (p *Pool[T]) Stop(ctx context.Context) (completed []Result[T], skipped int, err error) {
+	p.mu.Lock()
+	if !p.running {
+		p.mu.Unlock()
+		return nil, 0, ErrNotRunning
+	}
+	p.stopped.Store(true)
+	p.stopScheduling()
+	p.closed = true
+	p.queued.Broadcast()
+	p.dequeued.Broadcast()
+	done := p.done
+	p.mu.Unlock()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	completed, _, _ = p.snapshot()
+	return completed, int(p.stopSkipped.Load()), err
+}
+
+// Copy the results and panics collected so far and count the jobs that have
+// not finished yet
+func (p *Pool[T]) snapshot() (results []Result[T], panics []PanicInfo, outstanding int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	results = append([]Result[T](nil), p.results...)
+	panics = append([]PanicInfo(nil), p.panics...)
+	sortResults(results)
+	sort.Slice(panics, func(i, j int) bool {
+		return panics[i].Index < panics[j].Index
+	})
+	return results, panics, p.added - p.finished
+}
+
+// Panics recovered from jobs once the pool was waited on
+func (p *Pool[T]) Panics() []PanicInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.panics)
 }
@@ -0,0 +1,814 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+func TestConcurrentAddJob(t *testing.T) {
+	const producers, jobs = 8, 200
+	pool := typed_goroutine.NewPool[int](producers*jobs, 4)
+	var wg sync.WaitGroup
+	for range producers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if _, err := pool.AddJob(func() (*int, error) { return &i, nil }); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	results, _ := pool.Wait()
+	if len(results) != producers*jobs {
+		t.Fatalf("got %d results, want %d", len(results), producers*jobs)
+	}
+	seen := make(map[int]bool)
+	for _, result := range results {
+		if seen[result.Index] {
+			t.Fatalf("index %d returned twice", result.Index)
+		}
+		seen[result.Index] = true
+	}
+}
+
+func TestAddJobRacingRun(t *testing.T) {
+	const producers, jobs = 8, 200
+	pool := typed_goroutine.NewPool[int](producers*jobs, 4)
+	var accepted atomic.Int32
+	var wg sync.WaitGroup
+	for range producers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				_, err := pool.AddJob(func() (*int, error) { return &i, nil })
+				switch {
+				case err == nil:
+					accepted.Add(1)
+				case errors.Is(err, typed_goroutine.ErrAlreadyRunning), errors.Is(err, typed_goroutine.ErrPoolFinished):
+					return
+				default:
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	results, _ := pool.Wait()
+	if len(results) != int(accepted.Load()) {
+		t.Fatalf("got %d results of %d accepted jobs", len(results), accepted.Load())
+	}
+}
+
+func TestResetRunsAnotherBatch(t *testing.T) {
+	pool := typed_goroutine.NewPool[string](4, 2)
+	for batch := range 3 {
+		for i := range batch + 2 {
+			if _, err := pool.AddJob(func() (*string, error) {
+				if i == 1 {
+					panic(fmt.Sprintf("batch %d", batch))
+				}
+				value := fmt.Sprintf("batch %d job %d", batch, i)
+				return &value, nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		results, panics := pool.Wait()
+		if len(results) != batch+2 {
+			t.Fatalf("batch %d: got %d results, want %d", batch, len(results), batch+2)
+		}
+		for _, result := range results {
+			if result.Index == 1 {
+				continue
+			}
+			if want := fmt.Sprintf("batch %d job %d", batch, result.Index); result.Value != want {
+				t.Fatalf("batch %d: got %q for job %d, want %q", batch, result.Value, result.Index, want)
+			}
+		}
+		if len(panics) != 1 || panics[0].Value != fmt.Sprintf("batch %d", batch) {
+			t.Fatalf("batch %d: got panics %+v, want only the one of this batch", batch, panics)
+		}
+		if err := pool.Reset(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestResetWhileRunning(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](1, 1)
+	release := make(chan struct{})
+	if _, err := pool.AddJob(func() (*int, error) {
+		<-release
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Reset(); !errors.Is(err, typed_goroutine.ErrAlreadyRunning) {
+		t.Fatalf("got %v, want ErrAlreadyRunning", err)
+	}
+	close(release)
+	pool.Wait()
+	if err := pool.Reset(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestZeroWorkersUsesEveryCPU(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](10, 0)
+	if got, want := pool.MaxWorkers(), uint(runtime.GOMAXPROCS(0)); got != want {
+		t.Fatalf("got %d workers, want %d", got, want)
+	}
+	for i := range 10 {
+		if _, err := pool.AddJob(func() (*int, error) { return &i, nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if results, _ := pool.Wait(); len(results) != 10 {
+		t.Fatalf("got %d results, want 10", len(results))
+	}
+}
+
+func TestMoreJobsThanHinted(t *testing.T) {
+	const hint = 4
+	pool := typed_goroutine.NewPool[int](hint, 2)
+	for i := range 2 * hint {
+		if _, err := pool.AddJob(func() (*int, error) { return &i, nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+	done := make(chan []typed_goroutine.Result[int])
+	go func() {
+		results, _ := pool.Wait()
+		done <- results
+	}()
+	select {
+	case results := <-done:
+		if len(results) != 2*hint {
+			t.Fatalf("got %d results, want %d", len(results), 2*hint)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait did not return")
+	}
+}
+
+func TestWeightedJobsShareWorkers(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](4, 3)
+	var running, most atomic.Int32
+	for range 3 {
+		if _, err := pool.AddJobWeighted(func() (*int, error) {
+			now := running.Add(1)
+			defer running.Add(-1)
+			for {
+				seen := most.Load()
+				if now <= seen || most.CompareAndSwap(seen, now) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			return nil, nil
+		}, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Gives back its weight although it panics, or the last job never runs
+	if _, err := pool.AddJobWeighted(func() (*int, error) { panic("heavy") }, 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.AddJobWeighted(noop, 3); err != nil {
+		t.Fatal(err)
+	}
+	results, panics := pool.Wait()
+	if len(results) != 5 || len(panics) != 1 {
+		t.Fatalf("got %d results and %d panics, want 5 and 1", len(results), len(panics))
+	}
+	if most.Load() != 1 {
+		t.Fatalf("%d jobs of weight 2 ran at once in 3 workers, want 1", most.Load())
+	}
+}
+
+func TestInvalidWeight(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](1, 3)
+	for _, weight := range []int64{0, -1, 4} {
+		if _, err := pool.AddJobWeighted(noop, weight); !errors.Is(err, typed_goroutine.ErrInvalidWeight) {
+			t.Fatalf("weight %d: got %v, want ErrInvalidWeight", weight, err)
+		}
+	}
+}
+
+func TestPauseAndResume(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](6, 2)
+	if err := pool.Pause(); !errors.Is(err, typed_goroutine.ErrNotRunning) {
+		t.Fatalf("Pause before Run returned %v, want ErrNotRunning", err)
+	}
+	gate := make(chan struct{})
+	var started atomic.Int32
+	for i := range 6 {
+		if _, err := pool.AddJob(func() (*int, error) {
+			if started.Add(1) <= 2 {
+				<-gate
+			}
+			return &i, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Resume(); !errors.Is(err, typed_goroutine.ErrNotPaused) {
+		t.Fatalf("Resume before Pause returned %v, want ErrNotPaused", err)
+	}
+	waitFor(t, func() bool { return started.Load() == 2 })
+	if err := pool.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	close(gate)
+	waitFor(t, func() bool { return pool.Stats().Running == 0 })
+	time.Sleep(20 * time.Millisecond)
+	if got := started.Load(); got != 2 {
+		t.Fatalf("%d jobs started while paused, want 2", got)
+	}
+	if err := pool.Resume(); err != nil {
+		t.Fatal(err)
+	}
+	results, _ := pool.Wait()
+	seen := make(map[int]bool)
+	for _, result := range results {
+		seen[result.Index] = true
+	}
+	if len(results) != 6 || len(seen) != 6 {
+		t.Fatalf("got %d results for %d jobs, want one for each of 6", len(results), len(seen))
+	}
+	if err := pool.Pause(); !errors.Is(err, typed_goroutine.ErrPoolFinished) {
+		t.Fatalf("Pause after Wait returned %v, want ErrPoolFinished", err)
+	}
+	if err := pool.Resume(); !errors.Is(err, typed_goroutine.ErrPoolFinished) {
+		t.Fatalf("Resume after Wait returned %v, want ErrPoolFinished", err)
+	}
+}
+
+func TestPauseAfterLastJobStarted(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](1, 1)
+	started, release := make(chan struct{}), make(chan struct{})
+	if _, err := pool.AddJob(func() (*int, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	if err := pool.Pause(); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	done := make(chan struct{})
+	go func() {
+		pool.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("paused pool without jobs left to start did not finish")
+	}
+}
+
+// Poll until done reports true, failing the test after 5 seconds
+func waitFor(t *testing.T, done func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !done() {
+		if time.Now().After(deadline) {
+			t.Fatal("timed out")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestShrinkMaxWorkersWhileRunning(t *testing.T) {
+	const first = 16
+	pool := typed_goroutine.NewPool[int](40, first)
+	gate := make(chan struct{})
+	var running, most atomic.Int32
+	for i := range 40 {
+		if _, err := pool.AddJob(func() (*int, error) {
+			now := running.Add(1)
+			defer running.Add(-1)
+			if i < first {
+				<-gate
+				return nil, nil
+			}
+			// Started after shrinking, by then at most two may run
+			for {
+				seen := most.Load()
+				if now <= seen || most.CompareAndSwap(seen, now) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return nil, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return running.Load() == first })
+	pool.SetMaxWorkers(2)
+	if got := pool.Stats().MaxWorkers; got != 2 {
+		t.Fatalf("got a limit of %d, want 2", got)
+	}
+	close(gate)
+	if results, _ := pool.Wait(); len(results) != 40 {
+		t.Fatalf("got %d results, want 40", len(results))
+	}
+	if got := most.Load(); got > 2 || got == 0 {
+		t.Fatalf("%d jobs ran at once after shrinking to 2", got)
+	}
+}
+
+func TestTryAddJobBeforeRun(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](0, 1, typed_goroutine.WithQueueSize[int](2))
+	for i := range 2 {
+		if !pool.TryAddJob(noop) {
+			t.Fatalf("job %d was not added below the queue size", i)
+		}
+	}
+	if pool.TryAddJob(noop) {
+		t.Fatal("job was added to a full queue")
+	}
+	if _, err := pool.AddJob(noop); !errors.Is(err, typed_goroutine.ErrQueueFull) {
+		t.Fatalf("AddJob to a full queue: got %v, want ErrQueueFull", err)
+	}
+	if results, _ := pool.Wait(); len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+}
+
+func TestTryAddJobOnceQueueDrains(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](0, 1,
+		typed_goroutine.WithDynamicSubmission[int](),
+		typed_goroutine.WithQueueSize[int](1),
+	)
+	started, release := make(chan struct{}), make(chan struct{})
+	if !pool.TryAddJob(func() (*int, error) {
+		close(started)
+		<-release
+		return nil, nil
+	}) {
+		t.Fatal("job was not added to an empty queue")
+	}
+	if pool.TryAddJob(noop) {
+		t.Fatal("job was added to a full queue")
+	}
+	if err := pool.Run(); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	// The only worker is busy, so the scheduler takes at most one job off
+	// the queue to wait for it
+	added := 0
+	for pool.TryAddJob(noop) {
+		if added++; added > 2 {
+			t.Fatal("queue took more jobs than it holds while the worker was busy")
+		}
+	}
+	if added == 0 {
+		t.Fatal("no job was added once the first one left the queue")
+	}
+	close(release)
+	waitFor(t, func() bool {
+		if !pool.TryAddJob(noop) {
+			return false
+		}
+		added++
+		return true
+	})
+	pool.Close()
+	if results, _ := pool.Wait(); len(results) != added+1 {
+		t.Fatalf("got %d results, want %d", len(results), added+1)
+	}
+}
+
+func TestMustWait(t *testing.T) {
+	errRefused := errors.New("refused")
+	for _, tc := range []struct {
+		name string
+		job  func() (*int, error)
+		// Whether MustWait panics, with an error wrapping want or a
+		// *PanicError if want is nil
+		fails bool
+		want  error
+	}{
+		{"success", func() (*int, error) { return nil, nil }, false, nil},
+		{"failure", func() (*int, error) { return nil, errRefused }, true, errRefused},
+		{"panic", func() (*int, error) { panic("oops") }, true, nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := typed_goroutine.NewPool[int](3, 2)
+			for i := range 2 {
+				if _, err := pool.AddJob(func() (*int, error) { return &i, nil }); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := pool.AddJob(tc.job); err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				r := recover()
+				if !tc.fails {
+					if r != nil {
+						t.Fatalf("panicked with %v", r)
+					}
+					return
+				}
+				err, _ := r.(error)
+				var jobErr *typed_goroutine.JobError
+				var panicErr *typed_goroutine.PanicError
+				if !errors.As(err, &jobErr) || jobErr.Index != 2 {
+					t.Fatalf("panicked with %v, want the error of job 2", r)
+				}
+				if tc.want != nil && !errors.Is(err, tc.want) || tc.want == nil && !errors.As(err, &panicErr) {
+					t.Fatalf("panicked with %v, want it to wrap the failure of the job", r)
+				}
+			}()
+			values := pool.MustWait()
+			if want := []int{0, 1, 0}; !reflect.DeepEqual(values, want) {
+				t.Fatalf("got %v, want %v", values, want)
+			}
+		})
+	}
+}
+
+func TestPanickedJobsHaveResults(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](10, 3)
+	for i := range 10 {
+		if _, err := pool.AddJob(func() (*int, error) {
+			if i == 3 || i == 7 {
+				panic(i)
+			}
+			return &i, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, panics := pool.Wait()
+	if len(results) != 10 || len(panics) != 2 {
+		t.Fatalf("got %d results and %d panics, want 10 and 2", len(results), len(panics))
+	}
+	for i, result := range results {
+		var panicErr *typed_goroutine.PanicError
+		panicked := errors.As(result.Error, &panicErr)
+		if result.Index != i || panicked != (i == 3 || i == 7) {
+			t.Fatalf("result %d is of job %d with error %v", i, result.Index, result.Error)
+		}
+	}
+	if panics[0].Index != 3 || panics[1].Index != 7 {
+		t.Fatalf("got panics of jobs %d and %d, want 3 and 7", panics[0].Index, panics[1].Index)
+	}
+}
+
+func TestCancelCause(t *testing.T) {
+	errCaller := errors.New("caller gave up")
+	for _, tc := range []struct {
+		name string
+		want error
+		opts []typed_goroutine.Option[int]
+		// Runs the pool, whose first job calls first before it returns
+		run   func(*typed_goroutine.Pool[int]) error
+		first func(*typed_goroutine.Pool[int]) error
+	}{
+		{
+			name: "fail fast",
+			want: typed_goroutine.ErrFailFastTriggered,
+			opts: []typed_goroutine.Option[int]{typed_goroutine.WithFailFast[int]()},
+			first: func(*typed_goroutine.Pool[int]) error {
+				return errors.New("broken")
+			},
+		},
+		{
+			name: "stop",
+			want: typed_goroutine.ErrPoolStopped,
+			first: func(p *typed_goroutine.Pool[int]) error {
+				// Returns right away rather than waiting for this job
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				p.Stop(ctx)
+				return nil
+			},
+		},
+		{
+			name: "deadline",
+			want: typed_goroutine.ErrPoolDeadlineExceeded,
+			opts: []typed_goroutine.Option[int]{typed_goroutine.WithDeadline[int](time.Now().Add(-time.Second))},
+		},
+		{
+			name: "caller",
+			want: errCaller,
+			run: func(p *typed_goroutine.Pool[int]) error {
+				ctx, cancel := context.WithCancelCause(context.Background())
+				cancel(errCaller)
+				return p.RunWithContext(ctx)
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			pool := typed_goroutine.NewPool[int](3, 1, tc.opts...)
+			if _, err := pool.AddJob(func() (*int, error) {
+				if tc.first != nil {
+					return nil, tc.first(pool)
+				}
+				return nil, nil
+			}); err != nil {
+				t.Fatal(err)
+			}
+			for range 2 {
+				if _, err := pool.AddJob(noop); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tc.run != nil {
+				if err := tc.run(pool); err != nil {
+					t.Fatal(err)
+				}
+			}
+			results, _ := pool.Wait()
+			for _, result := range results[1:] {
+				if !result.Skipped || !errors.Is(result.Error, tc.want) {
+					t.Fatalf("job %d: got %v, want it skipped with %v", result.Index, result.Error, tc.want)
+				}
+			}
+			if err := pool.CancelCause(); !errors.Is(err, tc.want) {
+				t.Fatalf("pool: got %v, want %v", err, tc.want)
+			}
+		})
+	}
+}
+
+func TestCancelCauseOfCompletedRun(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](1, 1)
+	if _, err := pool.AddJob(noop); err != nil {
+		t.Fatal(err)
+	}
+	pool.Wait()
+	if err := pool.CancelCause(); err != nil {
+		t.Fatalf("got %v for a run that completed", err)
+	}
+}
+
+func TestWaitReturnsCopies(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](3, 2)
+	for i := range 3 {
+		if _, err := pool.AddJob(func() (*int, error) {
+			if i == 2 {
+				panic("oops")
+			}
+			return &i, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	results, panics := pool.Wait()
+	want, wantPanics := slices.Clone(results), slices.Clone(panics)
+	results[0].Index, results[1].Error = 7, errors.New("changed")
+	panics[0].Index = 7
+	_ = append(results[:1], typed_goroutine.Result[int]{Index: 9})
+	again, againPanics := pool.Wait()
+	if !reflect.DeepEqual(again, want) || !reflect.DeepEqual(againPanics, wantPanics) {
+		t.Fatalf("changing the results of Wait changed those of the pool")
+	}
+	if jobs := pool.Summary().Jobs; !reflect.DeepEqual(jobs, want) {
+		t.Fatalf("changing the results of Wait changed the summary")
+	}
+	dst := make([]typed_goroutine.Result[int], 0, 8)
+	into := pool.WaitInto(dst)
+	if !reflect.DeepEqual(into, want) || &into[0] != &dst[:1][0] {
+		t.Fatalf("WaitInto did not fill the storage it was given")
+	}
+}
+
+func noop() (*int, error) { return nil, nil }
+
+// Run jobs no-op jobs on a pool of workers
+func runNoop(b *testing.B, jobs, workers int, opts ...typed_goroutine.Option[int]) {
+	b.Helper()
+	pool := typed_goroutine.NewPool[int](uint(jobs), uint(workers), opts...)
+	for range jobs {
+		if _, err := pool.AddJob(noop); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if results, _ := pool.Wait(); len(results) != jobs {
+		b.Fatalf("got %d results, want %d", len(results), jobs)
+	}
+}
+
+// Worker loop of the pool against starting a goroutine per job that waits
+// for a semaphore, as the pool used to
+func BenchmarkPool_100kNoopJobs(b *testing.B) {
+	const jobs, workers = 100_000, 8
+	b.Run("pool", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			runNoop(b, jobs, workers)
+		}
+	})
+	b.Run("goroutine per job", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			sem := make(chan struct{}, workers)
+			results := make(chan typed_goroutine.Result[int], jobs)
+			var wg sync.WaitGroup
+			for i := range jobs {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					sem <- struct{}{}
+					defer func() { <-sem }()
+					value, err := noop()
+					results <- typed_goroutine.Result[int]{Result: value, Error: err, Index: i}
+				}()
+			}
+			wg.Wait()
+			close(results)
+			collected := make([]typed_goroutine.Result[int], 0, jobs)
+			for result := range results {
+				collected = append(collected, result)
+			}
+		}
+	})
+}
+
+type point struct{ X, Y int }
+
+// Returning small structs by value saves allocating a pointer per job
+func BenchmarkAddJobV(b *testing.B) {
+	const jobs = 10_000
+	b.Run("pointer", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			pool := typed_goroutine.NewPool[point](jobs, 4)
+			for i := range jobs {
+				if _, err := pool.AddJob(func() (*point, error) { return &point{i, i}, nil }); err != nil {
+					b.Fatal(err)
+				}
+			}
+			pool.Wait()
+		}
+	})
+	b.Run("value", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			pool := typed_goroutine.NewPool[point](jobs, 4)
+			for i := range jobs {
+				if _, err := pool.AddJobV(func() (point, error) { return point{i, i}, nil }); err != nil {
+					b.Fatal(err)
+				}
+			}
+			pool.Wait()
+		}
+	})
+}
+
+// Handing every worker batches of jobs against one job at a time
+func BenchmarkBatchSize(b *testing.B) {
+	const jobs, workers = 1_000_000, 8
+	for _, size := range []uint{1, 64} {
+		b.Run(fmt.Sprintf("batch=%d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				runNoop(b, jobs, workers, typed_goroutine.WithBatchSize[int](size))
+			}
+		})
+	}
+}
+
+// Memory of collecting the results of 1M jobs, which grows with the results
+// kept rather than with the hint given to NewPool
+func BenchmarkResultMemory(b *testing.B) {
+	const jobs, workers = 1_000_000, 8
+	for _, tc := range []struct {
+		name string
+		hint uint
+		opts []typed_goroutine.Option[int]
+	}{
+		{"hinted", jobs, nil},
+		{"unhinted", 0, nil},
+		{"discarded", jobs, []typed_goroutine.Option[int]{typed_goroutine.WithDiscardResults[int]()}},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				pool := typed_goroutine.NewPool[int](tc.hint, workers, tc.opts...)
+				for range jobs {
+					if _, err := pool.AddJob(noop); err != nil {
+						b.Fatal(err)
+					}
+				}
+				pool.Wait()
+			}
+		})
+	}
+}
+
+// Per job cost of no-op jobs, run with -benchmem to see the allocations
+func BenchmarkPool_NoopJobs(b *testing.B) {
+	for _, workers := range []int{1, 8, 64} {
+		for _, jobs := range []int{100, 10_000} {
+			b.Run(fmt.Sprintf("workers=%d/jobs=%d", workers, jobs), func(b *testing.B) {
+				b.ReportAllocs()
+				for range b.N {
+					runNoop(b, jobs, workers)
+				}
+			})
+		}
+	}
+}
+
+// Copying the results of Wait against running the jobs, and against
+// WaitInto reusing the storage of the results
+func BenchmarkWaitCopy(b *testing.B) {
+	const jobs = 10_000
+	b.Run("run", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			runNoop(b, jobs, 8)
+		}
+	})
+	pool := typed_goroutine.NewPool[int](jobs, 8)
+	for range jobs {
+		if _, err := pool.AddJob(noop); err != nil {
+			b.Fatal(err)
+		}
+	}
+	pool.Wait()
+	b.Run("Wait", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			pool.Wait()
+		}
+	})
+	b.Run("WaitInto", func(b *testing.B) {
+		b.ReportAllocs()
+		var dst []typed_goroutine.Result[int]
+		for range b.N {
+			dst = pool.WaitInto(dst[:0])
+		}
+	})
+}
+
+// Storage of a streaming run of 500k jobs sized up front with
+// SetExpectedJobs against growing as the jobs arrive
+func BenchmarkSetExpectedJobs(b *testing.B) {
+	const jobs = 500_000
+	for _, hint := range []bool{false, true} {
+		b.Run(fmt.Sprintf("hint=%t", hint), func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				pool := typed_goroutine.NewPool[int](0, 8, typed_goroutine.WithDynamicSubmission[int]())
+				if hint {
+					pool.SetExpectedJobs(jobs)
+				}
+				if err := pool.Run(); err != nil {
+					b.Fatal(err)
+				}
+				for range jobs {
+					if _, err := pool.Submit(noop); err != nil {
+						b.Fatal(err)
+					}
+				}
+				pool.Close()
+				if results, _ := pool.Wait(); len(results) != jobs {
+					b.Fatalf("got %d results, want %d", len(results), jobs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+// Package typedpooltest provides helpers for testing code that uses
+// typed_goroutine pools, such as a fake clock that only moves when told to:
+//
+//	clock := typedpooltest.NewClock(time.Now())
+//	pool := typed_goroutine.NewPool[int](0, 4,
+//		typed_goroutine.WithClock[int](clock),
+//		typed_goroutine.WithRetries[int](3, backoff),
+//	)
+//	pool.Run()
+//	clock.BlockUntil(1)
+//	clock.Advance(time.Second)
+//
+// FakeExecutor stands in for a pool behind the Executor interface and runs
+// jobs inline.
+package typedpooltest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+// Fake clock whose time only changes through Advance, safe for concurrent
+// use
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+	// Timers that have not fired or been stopped yet
+	timers []*timer
+	// Closed whenever a timer is added, so BlockUntil can check again
+	added chan struct{}
+}
+
+// Create a fake clock starting at now
+func NewClock(now time.Time) *Clock {
+	return &Clock{now: now, added: make(chan struct{})}
+}
+
+// Current time of the clock
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Create a timer that fires once the clock was advanced by d
+func (c *Clock) NewTimer(d time.Duration) typed_goroutine.Timer {
+	t := &timer{clock: c, c: make(chan time.Time, 1)}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.schedule(t, d)
+	return t
+}
+
+// Channel receiving the time once the clock was advanced by d
+func (c *Clock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// Block until the clock was advanced by d
+func (c *Clock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+// Move the clock forward by d, firing the timers that expire on the way in
+// the order of their deadlines
+func (c *Clock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	end := c.now.Add(d)
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+	for len(c.timers) > 0 && !c.timers[0].deadline.After(end) {
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		c.now = t.deadline
+		t.fire(c.now)
+	}
+	c.now = end
+}
+
+// Block until at least n timers are waiting to fire, so a test knows the
+// code under test reached a wait before advancing the clock
+func (c *Clock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		waiting, added := len(c.timers), c.added
+		c.mu.Unlock()
+		if waiting >= n {
+			return
+		}
+		<-added
+	}
+}
+
+// Number of timers waiting to fire
+func (c *Clock) Timers() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// Add t to fire after d, must be called with the lock held
+func (c *Clock) schedule(t *timer, d time.Duration) {
+	t.deadline = c.now.Add(d)
+	if d <= 0 {
+		t.fire(c.now)
+		return
+	}
+	c.timers = append(c.timers, t)
+	close(c.added)
+	c.added = make(chan struct{})
+}
+
+// Remove t, reports whether it was waiting to fire. Must be called with the
+// lock held.
+func (c *Clock) unschedule(t *timer) bool {
+	for i, waiting := range c.timers {
+		if waiting == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Timer of a fake clock
+type timer struct {
+	clock    *Clock
+	c        chan time.Time
+	deadline time.Time
+}
+
+func (t *timer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *timer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.unschedule(t)
+}
+
+func (t *timer) Reset(d time.Duration) bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	active := t.clock.unschedule(t)
+	// Like a timer of the time package since Go 1.23, no stale value is
+	// received after Reset
+	select {
+	case <-t.c:
+	default:
+	}
+	t.clock.schedule(t, d)
+	return active
+}
+
+// Deliver now without blocking, the channel holds at most one value
+func (t *timer) fire(now time.Time) {
+	select {
+	case t.c <- now:
+	default:
+	}
+}
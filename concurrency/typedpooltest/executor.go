@@ -0,0 +1,108 @@
+package typedpooltest
+
+import (
+	"context"
+	"runtime/debug"
+	"strconv"
+	"sync"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+// Executor that runs every job inline on the goroutine calling Await, one
+// after the other in the order they were added, and records how it was
+// used. Outcomes can be forced with Return and Panic. The zero value is
+// ready to use and it is safe for concurrent use.
+type FakeExecutor[T any] struct {
+	mu      sync.Mutex
+	jobs    []func(ctx context.Context) (*T, error)
+	forced  map[int]func() (*T, error)
+	results []typed_goroutine.Result[T]
+	awaits  int
+}
+
+var _ typed_goroutine.Executor[int] = (*FakeExecutor[int])(nil)
+
+// Record a job, it runs on the next call to Await
+func (f *FakeExecutor[T]) Add(job func(ctx context.Context) (*T, error)) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.jobs = append(f.jobs, job)
+	return nil
+}
+
+// Run the jobs added since the last call with ctx and return the results
+// of all jobs so far, labelled like those of a pool
+func (f *FakeExecutor[T]) Await(ctx context.Context) ([]typed_goroutine.Result[T], error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.awaits++
+	for index := len(f.results); index < len(f.jobs); index++ {
+		job := f.jobs[index]
+		if forced, ok := f.forced[index]; ok {
+			job = func(context.Context) (*T, error) {
+				return forced()
+			}
+		}
+		f.results = append(f.results, run(ctx, index, job))
+	}
+	return append([]typed_goroutine.Result[T](nil), f.results...), nil
+}
+
+// Make the job at index return value and err instead of running
+func (f *FakeExecutor[T]) Return(index int, value *T, err error) {
+	f.force(index, func() (*T, error) {
+		return value, err
+	})
+}
+
+// Make the job at index panic with value instead of running
+func (f *FakeExecutor[T]) Panic(index int, value interface{}) {
+	f.force(index, func() (*T, error) {
+		panic(value)
+	})
+}
+
+func (f *FakeExecutor[T]) force(index int, job func() (*T, error)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.forced == nil {
+		f.forced = make(map[int]func() (*T, error))
+	}
+	f.forced[index] = job
+}
+
+// Number of jobs added so far
+func (f *FakeExecutor[T]) Added() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.jobs)
+}
+
+// Number of calls to Await so far
+func (f *FakeExecutor[T]) Awaited() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.awaits
+}
+
+// Run a job and describe its outcome like a pool does
+func run[T any](ctx context.Context, index int, job func(ctx context.Context) (*T, error)) (result typed_goroutine.Result[T]) {
+	result = typed_goroutine.Result[T]{Index: index, Name: strconv.Itoa(index), Meta: typed_goroutine.Meta{Attempts: 1}}
+	defer func() {
+		if r := recover(); r != nil {
+			result.Panic = &typed_goroutine.PanicInfo{Value: r, Index: index, Name: result.Name, Stack: debug.Stack(), Attempts: 1}
+			result.Error = &typed_goroutine.JobError{Index: index, Name: result.Name, Attempt: 1, Err: &typed_goroutine.PanicError{Value: r, Stack: result.Panic.Stack}}
+		}
+	}()
+	value, err := job(ctx)
+	if err != nil {
+		result.Error = &typed_goroutine.JobError{Index: index, Name: result.Name, Attempt: 1, Err: err}
+		return result
+	}
+	result.Result, result.OK = value, true
+	if value != nil {
+		result.Value = *value
+	}
+	return result
+}
@@ -0,0 +1,54 @@
+package typed_goroutine
+
+import "context"
+
+// Add a job that may succeed or fail with warnings, non-fatal problems
+// such as skipped rows that end up in Result.Warnings apart from its error.
+// See Warn.
+func (p *Pool[T]) AddJobW(job func() (*T, []error, error)) (*JobHandle[T], error) {
+	return p.add(queuedJob[T]{fn: func(ctx context.Context) (*T, error) {
+		result, warnings, err := job()
+		for _, warning := range warnings {
+			Warn(ctx, warning)
+		}
+		return result, err
+	}}, false)
+}
+
+// Attach a warning to the running job, which is reported in
+// Result.Warnings whether the job succeeds or fails. Warnings never make a
+// job fail, they do not count towards WithFailFast, WithMaxErrors or
+// retries, and the warnings of an attempt that was retried are kept. A job
+// that panics drops its warnings. Does nothing when ctx does not belong to
+// a job, the job already ended or warning is nil.
+func Warn(ctx context.Context, warning error) {
+	if warning == nil {
+		return
+	}
+	if scope, ok := ctx.Value(jobInfoKey{}).(*jobScope); ok {
+		scope.mu.Lock()
+		defer scope.mu.Unlock()
+		if !scope.done {
+			scope.warnings = append(scope.warnings, warning)
+		}
+	}
+}
+
+// Warnings of every job of results in the order of the results, each a
+// *JobError carrying the index of its job. Nil if no job warned.
+func AllWarnings[T any](results []Result[T]) []error {
+	var warnings []error
+	for _, result := range results {
+		for _, warning := range result.Warnings {
+			warnings = append(warnings, &JobError{Index: result.Index, Name: result.Name, Attempt: result.Attempts, Err: warning})
+		}
+	}
+	return warnings
+}
+
+// Warnings attached to the job so far
+func (s *jobScope) warned() []error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.warnings
+}
@@ -0,0 +1,68 @@
+package typed_goroutine_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+)
+
+func TestWarnings(t *testing.T) {
+	errRow, errField, errRefused := errors.New("skipped row 7"), errors.New("deprecated field"), errors.New("refused")
+	// Warnings must not make the pool fail fast, or job 1 would be skipped
+	pool := typed_goroutine.NewPool[int](3, 1, typed_goroutine.WithFailFast[int]())
+	if _, err := pool.AddJobW(func() (*int, []error, error) {
+		value := 1
+		return &value, []error{errRow, errField}, nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.AddJob(noop); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.AddJobCtx(func(ctx context.Context) (*int, error) {
+		typed_goroutine.Warn(ctx, errField)
+		return nil, errRefused
+	}); err != nil {
+		t.Fatal(err)
+	}
+	results, _ := pool.Wait()
+	if !results[0].OK || len(results[0].Warnings) != 2 {
+		t.Fatalf("job 0: got %v with warnings %v, want success with 2 warnings", results[0].Error, results[0].Warnings)
+	}
+	if !results[1].OK || len(results[1].Warnings) != 0 {
+		t.Fatalf("job 1: got %v with warnings %v, want success without warnings", results[1].Error, results[1].Warnings)
+	}
+	if !errors.Is(results[2].Error, errRefused) || len(results[2].Warnings) != 1 {
+		t.Fatalf("job 2: got %v with warnings %v, want its error and 1 warning", results[2].Error, results[2].Warnings)
+	}
+
+	warnings := typed_goroutine.AllWarnings(results)
+	if len(warnings) != 3 {
+		t.Fatalf("got %d warnings, want 3", len(warnings))
+	}
+	for i, want := range []struct {
+		index int
+		err   error
+	}{{0, errRow}, {0, errField}, {2, errField}} {
+		var jobErr *typed_goroutine.JobError
+		if !errors.As(warnings[i], &jobErr) || jobErr.Index != want.index || !errors.Is(warnings[i], want.err) {
+			t.Fatalf("warning %d: got %v, want %v of job %d", i, warnings[i], want.err, want.index)
+		}
+	}
+}
+
+func TestPanicDropsWarnings(t *testing.T) {
+	pool := typed_goroutine.NewPool[int](1, 1)
+	if _, err := pool.AddJobCtx(func(ctx context.Context) (*int, error) {
+		typed_goroutine.Warn(ctx, errors.New("skipped row 7"))
+		panic("oops")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	results, panics := pool.Wait()
+	if len(panics) != 1 || len(results[0].Warnings) != 0 {
+		t.Fatalf("got %d panics and warnings %v, want 1 panic and no warnings", len(panics), results[0].Warnings)
+	}
+}
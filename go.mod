@@ -1,5 +1,5 @@
 module github.com/demy076/typed_goroutines
 
-go 1.21.5
+go 1.23
 
-require golang.org/x/sync v0.5.0
+require golang.org/x/time v0.5.0
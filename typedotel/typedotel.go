@@ -0,0 +1,74 @@
+// Package typedotel traces the jobs of typed_goroutine pools with
+// OpenTelemetry. It lives in its own module so the pool itself does not
+// depend on OpenTelemetry.
+//
+// Every job gets a span that is a child of the span in the run context of
+// the pool, so the trace of the caller continues into the jobs:
+//
+//	pool := typed_goroutine.NewPool[int](0, 4,
+//		typedotel.WithTracing[int](otel.Tracer("crawler")),
+//	)
+//	pool.AddJobCtx(func(ctx context.Context) (*int, error) {
+//		// ctx carries the span of the job
+//		return fetch(ctx)
+//	})
+//	pool.WaitWithContext(ctx)
+package typedotel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Start a span for every job of the pool with tracer, named after the pool
+// and the name of the job, which defaults to its index. The span ends once
+// the job returned, with an error status if it failed or panicked.
+func WithTracing[T any](tracer trace.Tracer) typed_goroutine.Option[T] {
+	return func(p *typed_goroutine.Pool[T]) error {
+		if tracer == nil {
+			return fmt.Errorf("%w: nil tracer", typed_goroutine.ErrInvalidOption)
+		}
+		return typed_goroutine.WithJobContext(func(ctx context.Context, index int, name string) (context.Context, func(typed_goroutine.Result[T])) {
+			ctx, span := tracer.Start(ctx, spanName(p.Name(), name), trace.WithAttributes(
+				attribute.Int("job.index", index),
+				attribute.String("job.name", name),
+			))
+			return ctx, func(result typed_goroutine.Result[T]) {
+				end(span, result)
+			}
+		})(p)
+	}
+}
+
+// Name of the span of a job
+func spanName(pool string, name string) string {
+	if pool == "" {
+		return "job " + name
+	}
+	return pool + " job " + name
+}
+
+// Record the outcome of a job on its span and end it
+func end[T any](span trace.Span, result typed_goroutine.Result[T]) {
+	defer span.End()
+	span.SetAttributes(attribute.Int("job.attempts", result.Attempts))
+	var panicErr *typed_goroutine.PanicError
+	switch {
+	case result.Panic != nil:
+		span.SetAttributes(attribute.Bool("job.panicked", true))
+		span.SetStatus(codes.Error, fmt.Sprintf("job panicked: %v", result.Panic.Value))
+	case errors.As(result.Error, &panicErr):
+		span.SetAttributes(attribute.Bool("job.panicked", true))
+		span.RecordError(result.Error)
+		span.SetStatus(codes.Error, result.Error.Error())
+	case result.Error != nil:
+		span.RecordError(result.Error)
+		span.SetStatus(codes.Error, result.Error.Error())
+	}
+}
@@ -0,0 +1,61 @@
+package typedotel_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	typed_goroutine "github.com/demy076/typed_goroutines/concurrency"
+	"github.com/demy076/typed_goroutines/typedotel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSpanPerJob(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer provider.Shutdown(context.Background())
+	tracer := provider.Tracer("test")
+
+	ctx, parent := tracer.Start(context.Background(), "caller")
+	pool := typed_goroutine.NewPool[int](3, 2,
+		typed_goroutine.WithName[int]("crawl"),
+		typedotel.WithTracing[int](tracer),
+	)
+	if _, err := pool.AddNamedJob("fetch", func() (*int, error) { return nil, nil }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.AddJob(func() (*int, error) { return nil, errors.New("refused") }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pool.AddJob(func() (*int, error) { panic("oops") }); err != nil {
+		t.Fatal(err)
+	}
+	pool.WaitWithContext(ctx)
+	parent.End()
+
+	spans := make(map[string]tracetest.SpanStub)
+	for _, span := range exporter.GetSpans() {
+		spans[span.Name] = span
+	}
+	if len(spans) != 4 {
+		t.Fatalf("got %d spans, want one for the caller and one per job", len(spans))
+	}
+	for name, status := range map[string]codes.Code{
+		"crawl job fetch": codes.Unset,
+		"crawl job 1":     codes.Error,
+		"crawl job 2":     codes.Error,
+	} {
+		span, ok := spans[name]
+		if !ok {
+			t.Fatalf("no span %q", name)
+		}
+		if span.Parent.SpanID() != parent.SpanContext().SpanID() || span.SpanContext.TraceID() != parent.SpanContext().TraceID() {
+			t.Fatalf("span %q is not a child of the caller", name)
+		}
+		if span.Status.Code != status {
+			t.Fatalf("span %q: got status %v, want %v", name, span.Status.Code, status)
+		}
+	}
+}